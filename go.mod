@@ -3,14 +3,19 @@ module github.com/hsanjuan/mhdw
 go 1.15
 
 require (
+	filippo.io/edwards25519 v1.0.0
+	github.com/Yawning/aez v0.0.0-20211027044916-e49e68abd344
 	github.com/btcsuite/btcd v0.21.0-beta
-	github.com/btcsuite/btcutil v1.0.2
+	github.com/btcsuite/btcutil v1.0.3-0.20201208143702-a53e38424cce
 	github.com/decred/dcrd/chaincfg v1.5.2
 	github.com/decred/dcrd/dcrec v1.0.0
 	github.com/decred/dcrd/dcrutil v1.4.0
 	github.com/decred/dcrd/hdkeychain v1.1.1
 	github.com/decred/dcrwallet/walletseed v1.0.3
 	github.com/ethereum/go-ethereum v1.9.23
+	github.com/tyler-smith/go-bip39 v1.1.0
 	github.com/urfave/cli v1.22.4
+	gitlab.com/yawning/bsaes.git v0.0.0-20190805113838-0a714cd429ec // indirect
 	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897
+	golang.org/x/text v0.3.3
 )
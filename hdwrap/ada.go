@@ -0,0 +1,274 @@
+package hdwrap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// CIP1852Purpose is the purpose path element of Cardano Shelley
+// derivation paths (m/1852'/1815'/account'/role/index).
+const CIP1852Purpose uint32 = 1852
+
+// Cardano key roles, the fourth element of CIP-1852 paths.
+const (
+	CardanoExternalRole uint32 = 0
+	CardanoInternalRole uint32 = 1
+	CardanoStakingRole  uint32 = 2
+)
+
+// Shelley address header types (CIP-19), which go in the 4 high bits of
+// the header byte. The low bits hold the network ID.
+const (
+	cardanoBaseHeader       byte = 0x00
+	cardanoEnterpriseHeader byte = 0x60
+	cardanoRewardHeader     byte = 0xe0
+)
+
+// cardanoNetworkIDs are the network IDs used in Shelley addresses. All
+// Cardano test networks (preprod, preview) use 0.
+var cardanoNetworkIDs = map[Network]byte{
+	Mainnet:  1,
+	Testnet3: 0,
+}
+
+// ErrWrongBIP39Passphrase is returned when a BIP39 passphrase does not
+// produce the seed of a seed file.
+var ErrWrongBIP39Passphrase = errors.New("wrong BIP39 passphrase")
+
+// AdaKey implements the Key interface for Cardano Shelley wallets:
+// Icarus master keys (CIP-3) and BIP32-Ed25519 derivation at
+// m/1852'/1815'/account'/role/index (CIP-1852).
+type AdaKey struct {
+	key      *BIP32Ed25519Key
+	net      Network
+	addrType AddressType
+}
+
+func (k *AdaKey) Type() KeyType {
+	return Ada
+}
+
+// SetNetwork allows producing addresses for Cardano test networks
+// (testnet3).
+func (k *AdaKey) SetNetwork(n Network) error {
+	if _, ok := cardanoNetworkIDs[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	k.net = n
+	return nil
+}
+
+// AddressType returns the type of addresses produced by this key. Base
+// addresses are produced by default.
+func (k *AdaKey) AddressType() AddressType {
+	switch k.addrType {
+	case CardanoEnterprise, CardanoReward:
+		return k.addrType
+	default:
+		return CardanoBase
+	}
+}
+
+// SetAddressType selects the type of addresses produced by this key
+// (base, enterprise or reward).
+func (k *AdaKey) SetAddressType(t AddressType) error {
+	if t.Purpose() != CIP1852Purpose {
+		return fmt.Errorf("address type %s is not supported", t)
+	}
+	k.addrType = t
+	return nil
+}
+
+// FromString initializes an AdaKey by importing a bech32 extended key,
+// as produced by cardano-address (root_xvk, acct_xvk, acct_xsk...), or a
+// hex-encoded one, as used by the cardano-wallet API.
+func (k *AdaKey) FromString(data string, priv bool) error {
+	data = strings.TrimSpace(data)
+	b, err := hex.DecodeString(data)
+	if err != nil {
+		var hrp string
+		hrp, b, err = bech32Decode(data)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(hrp, "_xvk") && !strings.HasSuffix(hrp, "_xsk") {
+			return fmt.Errorf("not a Cardano extended key: %s", hrp)
+		}
+		b, err = convertBits(b, 5, 8, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := parseBIP32Ed25519Key(b)
+	if err != nil {
+		return err
+	}
+	if priv && !key.IsPrivate() {
+		return fmt.Errorf("given key was not a private key")
+	}
+	k.key = key
+	return nil
+}
+
+// FromSeed initializes an AdaKey using the Seed as Icarus entropy, with
+// no passphrase. Keys for BIP39 seeds, as used by Cardano wallets, are
+// initialized with FromBIP39 instead.
+func (k *AdaKey) FromSeed(s Seed) error {
+	k.key = NewIcarusMaster(s, "")
+	return nil
+}
+
+// FromBIP39 initializes an AdaKey from the entropy of a BIP39 seed file
+// and the BIP39 passphrase, as Cardano wallets do. The passphrase is
+// verified against the seed.
+func (k *AdaKey) FromBIP39(f *SeedFile, passphrase string) error {
+	if f.Scheme != SchemeBIP39 {
+		return fmt.Errorf("not a BIP39 seed file")
+	}
+	words, err := f.Mnemonic()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(bip39Seed(strings.Join(words, " "), passphrase), f.Seed) {
+		return ErrWrongBIP39Passphrase
+	}
+	k.key = NewIcarusMaster(f.Entropy, passphrase)
+	return nil
+}
+
+// GetMasterPub returns the bech32-encoded master public key (root_xvk...).
+func (k *AdaKey) GetMasterPub() (string, error) {
+	return bech32EncodeBytes("root_xvk", k.key.Neuter().Bytes()), nil
+}
+
+// GetMasterPriv returns the bech32-encoded master private key
+// (root_xsk...).
+func (k *AdaKey) GetMasterPriv() (string, error) {
+	if !k.key.IsPrivate() {
+		return "", fmt.Errorf("not a private key")
+	}
+	return bech32EncodeBytes("root_xsk", k.key.Bytes()), nil
+}
+
+// AccountPath returns m/1852'/1815'/account'.
+func (k *AdaKey) AccountPath(account uint32) Path {
+	return AccountPath(CIP1852Purpose, Ada.CoinType(), account)
+}
+
+// AddressPath returns m/1852'/1815'/account'/role/index, where the
+// change argument is the role: 0 for external and 1 for internal
+// (change) addresses.
+func (k *AdaKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+// GetAccountPub returns the account public key (acct_xvk...), which
+// allows watch-only wallets, like cardano-wallet, to derive the
+// addresses of the account.
+func (k *AdaKey) GetAccountPub(account uint32) (string, error) {
+	key, err := k.key.Derive(k.AccountPath(account))
+	if err != nil {
+		return "", err
+	}
+	return bech32EncodeBytes("acct_xvk", key.Neuter().Bytes()), nil
+}
+
+// GetChildPrivKey derivates the private key of the address produced by
+// GetChildPubKey: the payment key (addr_xsk...) or, for reward
+// addresses, the stake key (stake_xsk...).
+func (k *AdaKey) GetChildPrivKey(p Path) (string, error) {
+	hrp := "addr_xsk"
+	if k.AddressType() == CardanoReward {
+		var err error
+		p, err = cardanoStakePath(p)
+		if err != nil {
+			return "", err
+		}
+		hrp = "stake_xsk"
+	}
+	key, err := k.key.Derive(p)
+	if err != nil {
+		return "", err
+	}
+	if !key.IsPrivate() {
+		return "", fmt.Errorf("not a private key")
+	}
+	return bech32EncodeBytes(hrp, key.Bytes()), nil
+}
+
+// GetChildPubKey derivates a key and returns its Shelley address. The
+// stake key of base and reward addresses is the one of the account
+// (role 2, index 0), which is derived by replacing the last two
+// elements of the path.
+func (k *AdaKey) GetChildPubKey(p Path) (string, error) {
+	netID := cardanoNetworkIDs[k.net]
+	hrp := "addr"
+	if k.net != Mainnet {
+		hrp = "addr_test"
+	}
+
+	switch k.AddressType() {
+	case CardanoEnterprise:
+		payment, err := k.credential(p)
+		if err != nil {
+			return "", err
+		}
+		return bech32EncodeBytes(hrp, append([]byte{cardanoEnterpriseHeader | netID}, payment...)), nil
+	case CardanoReward:
+		sp, err := cardanoStakePath(p)
+		if err != nil {
+			return "", err
+		}
+		stake, err := k.credential(sp)
+		if err != nil {
+			return "", err
+		}
+		hrp = strings.Replace(hrp, "addr", "stake", 1)
+		return bech32EncodeBytes(hrp, append([]byte{cardanoRewardHeader | netID}, stake...)), nil
+	default:
+		payment, err := k.credential(p)
+		if err != nil {
+			return "", err
+		}
+		sp, err := cardanoStakePath(p)
+		if err != nil {
+			return "", err
+		}
+		stake, err := k.credential(sp)
+		if err != nil {
+			return "", err
+		}
+		addr := append([]byte{cardanoBaseHeader | netID}, payment...)
+		return bech32EncodeBytes(hrp, append(addr, stake...)), nil
+	}
+}
+
+// credential derivates a key and returns its Blake2b-224 hash, which
+// identifies it in addresses.
+func (k *AdaKey) credential(p Path) ([]byte, error) {
+	key, err := k.key.Derive(p)
+	if err != nil {
+		return nil, err
+	}
+	h, err := blake2b.New(28, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(key.PublicKey())
+	return h.Sum(nil), nil
+}
+
+// cardanoStakePath returns the path of the stake key of the account of
+// the given address path (role/index replaced by 2/0).
+func cardanoStakePath(p Path) (Path, error) {
+	if len(p) < 2 {
+		return nil, fmt.Errorf("stake keys need a path ending in role/index")
+	}
+	return p[:len(p)-2].Child(CardanoStakingRole, 0), nil
+}
@@ -0,0 +1,107 @@
+package hdwrap
+
+import "testing"
+
+// cip19Mnemonic is the mnemonic of the CIP-19 test vectors, which use
+// the keys at m/1852'/1815'/0'/0/0 (payment) and m/1852'/1815'/0'/2/0
+// (stake).
+const cip19Mnemonic = "test walk nut penalty hip pave soap entry language right filter choice"
+
+func cip19Key(t *testing.T, passphrase string) *AdaKey {
+	t.Helper()
+	entropy, err := DecodeBIP39(cip19Mnemonic, English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err := NewBIP39SeedFile(entropy, English, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &AdaKey{}
+	if err := k.FromBIP39(sf, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestAdaAddresses(t *testing.T) {
+	tests := []struct {
+		network  Network
+		addrType AddressType
+		address  string
+	}{
+		{Mainnet, CardanoBase, "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3jcu5d8ps7zex2k2xt3uqxgjqnnj83ws8lhrn648jjxtwqfjkjv7"},
+		{Testnet3, CardanoBase, "addr_test1qz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3jcu5d8ps7zex2k2xt3uqxgjqnnj83ws8lhrn648jjxtwq2ytjqp"},
+		{Mainnet, CardanoEnterprise, "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"},
+		{Testnet3, CardanoEnterprise, "addr_test1vz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzerspjrlsz"},
+		{Mainnet, CardanoReward, "stake1uyevw2xnsc0pvn9t9r9c7qryfqfeerchgrlm3ea2nefr9hqxdekzz"},
+		{Testnet3, CardanoReward, "stake_test1uqevw2xnsc0pvn9t9r9c7qryfqfeerchgrlm3ea2nefr9hqp8n5xl"},
+	}
+
+	k := cip19Key(t, "")
+	for _, tc := range tests {
+		if err := k.SetNetwork(tc.network); err != nil {
+			t.Fatal(err)
+		}
+		if err := k.SetAddressType(tc.addrType); err != nil {
+			t.Fatal(err)
+		}
+		addr, err := k.GetChildPubKey(k.AddressPath(0, 0, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != tc.address {
+			t.Errorf("%s %s: %s, expected %s", tc.network, tc.addrType, addr, tc.address)
+		}
+	}
+}
+
+func TestAdaExtendedKeys(t *testing.T) {
+	k := cip19Key(t, "")
+	root, err := k.GetMasterPub()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "root_xvk1ulhlm5wqg24wrdvxphvjtys8lcq83hyz8x2egsgp9eqyjy5xqgvn583m6hw0z875lxy7ctxum6362ndcn9ees96wehxgwqrvyaqhdgq5vduvt"; root != expected {
+		t.Errorf("root key %s, expected %s", root, expected)
+	}
+	acct, err := k.GetAccountPub(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "acct_xvk1eame4ge0x5yrwpuqs5eyw89kfmjpgfkfh02xzdx6c2k9k2swcr5clf0u634tm82x6nv2j750x3j7938g70ya4k0lv6pr59s7etw2vpqgfmule"; acct != expected {
+		t.Errorf("account key %s, expected %s", acct, expected)
+	}
+
+	// Watch-only keys produce the same addresses.
+	watch := &AdaKey{}
+	if err := watch.FromString(acct, false); err != nil {
+		t.Fatal(err)
+	}
+	addr, err := watch.GetChildPubKey(Path{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := k.GetChildPubKey(k.AddressPath(0, 0, 0))
+	if addr != expected {
+		t.Errorf("watch-only address %s, expected %s", addr, expected)
+	}
+
+	if err := watch.FromString(acct, true); err == nil {
+		t.Error("expected an error importing a public key as private")
+	}
+}
+
+func TestAdaBIP39Passphrase(t *testing.T) {
+	entropy, _ := DecodeBIP39(cip19Mnemonic, English)
+	sf, err := NewBIP39SeedFile(entropy, English, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&AdaKey{}).FromBIP39(sf, "wrong"); err != ErrWrongBIP39Passphrase {
+		t.Errorf("expected ErrWrongBIP39Passphrase, got %v", err)
+	}
+	if err := (&AdaKey{}).FromBIP39(sf, "secret"); err != nil {
+		t.Error(err)
+	}
+}
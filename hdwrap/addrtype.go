@@ -0,0 +1,125 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported address types
+const (
+	// P2PKH are legacy pay-to-pubkey-hash addresses (1...). They are
+	// derived at BIP44 paths and exported as xpub/tpub keys.
+	P2PKH AddressType = iota
+	// P2WPKH are native segwit pay-to-witness-pubkey-hash addresses
+	// (bc1q...). They are derived at BIP84 paths and exported as
+	// zpub/vpub keys (SLIP-132).
+	P2WPKH
+	// P2SHP2WPKH are nested segwit addresses, where a P2WPKH script
+	// is wrapped in a pay-to-script-hash (3...). They are derived at
+	// BIP49 paths and exported as ypub/upub keys (SLIP-132).
+	P2SHP2WPKH
+	// P2TR are taproot pay-to-taproot addresses (bc1p...) for key-path
+	// spending (BIP86). They are exported as xpub/tpub keys.
+	P2TR
+	// P2WSH are native segwit pay-to-witness-script-hash addresses
+	// (bc1q...) of multisig wallets, which cannot be derived from a
+	// single key. The type is only used to export the cosigner keys of
+	// Electrum 2FA segwit wallets, as Zpub/Vpub keys (SLIP-132).
+	P2WSH
+	// CardanoBase are Cardano Shelley base addresses (addr1q...), which
+	// hold the payment key and the stake key of the account (CIP-19).
+	CardanoBase
+	// CardanoEnterprise are Cardano Shelley addresses with a payment
+	// key and no staking rights (addr1v...).
+	CardanoEnterprise
+	// CardanoReward are Cardano reward (stake) addresses of the
+	// account's stake key (stake1u...).
+	CardanoReward
+)
+
+// AddressType identifies the kind of payment address generated
+// for a Key.
+type AddressType int
+
+var addressTypeMap = map[string]AddressType{
+	"p2pkh":       P2PKH,
+	"p2wpkh":      P2WPKH,
+	"p2sh-p2wpkh": P2SHP2WPKH,
+	"p2tr":        P2TR,
+	"p2wsh":       P2WSH,
+	"base":        CardanoBase,
+	"enterprise":  CardanoEnterprise,
+	"reward":      CardanoReward,
+}
+
+// ParseAddressType returns the AddressType for the given name (p2pkh,
+// p2wpkh, p2sh-p2wpkh, p2tr, base, enterprise or reward).
+func ParseAddressType(s string) (AddressType, error) {
+	t, ok := addressTypeMap[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown address type: %s", s)
+	}
+	return t, nil
+}
+
+// String returns the string representation of an AddressType
+func (t AddressType) String() string {
+	for k, v := range addressTypeMap {
+		if v == t {
+			return k
+		}
+	}
+	panic("bad address type")
+}
+
+// Purpose returns the purpose path element used for derivation paths
+// of this address type (BIP44, BIP49, BIP84, BIP86, CIP-1852).
+func (t AddressType) Purpose() uint32 {
+	switch t {
+	case P2PKH:
+		return BIP44Purpose
+	case P2WPKH:
+		return 84
+	case P2SHP2WPKH:
+		return 49
+	case P2TR:
+		return 86
+	case CardanoBase, CardanoEnterprise, CardanoReward:
+		return CIP1852Purpose
+	default:
+		panic("bad address type")
+	}
+}
+
+// An AddressTyper is a Key which can produce several types of
+// addresses.
+type AddressTyper interface {
+	AddressType() AddressType
+	SetAddressType(t AddressType) error
+}
+
+// hdVersions holds the version bytes used to serialize extended
+// keys (SLIP-132).
+type hdVersions struct {
+	pub  [4]byte
+	priv [4]byte
+}
+
+var (
+	xpubVersions = hdVersions{[4]byte{0x04, 0x88, 0xb2, 0x1e}, [4]byte{0x04, 0x88, 0xad, 0xe4}}
+	tpubVersions = hdVersions{[4]byte{0x04, 0x35, 0x87, 0xcf}, [4]byte{0x04, 0x35, 0x83, 0x94}}
+	ypubVersions = hdVersions{[4]byte{0x04, 0x9d, 0x7c, 0xb2}, [4]byte{0x04, 0x9d, 0x78, 0x78}}
+	upubVersions = hdVersions{[4]byte{0x04, 0x4a, 0x52, 0x62}, [4]byte{0x04, 0x4a, 0x4e, 0x28}}
+	zpubVersions = hdVersions{[4]byte{0x04, 0xb2, 0x47, 0x46}, [4]byte{0x04, 0xb2, 0x43, 0x0c}}
+	vpubVersions = hdVersions{[4]byte{0x04, 0x5f, 0x1c, 0xf6}, [4]byte{0x04, 0x5f, 0x18, 0xbc}}
+	// P2WSH multisig (Zpub/Vpub)
+	zpubMultisigVersions = hdVersions{[4]byte{0x02, 0xaa, 0x7e, 0xd3}, [4]byte{0x02, 0xaa, 0x7a, 0x99}}
+	vpubMultisigVersions = hdVersions{[4]byte{0x02, 0x57, 0x54, 0x83}, [4]byte{0x02, 0x57, 0x50, 0x48}}
+	// Litecoin
+	ltubVersions = hdVersions{[4]byte{0x01, 0x9d, 0xa4, 0x62}, [4]byte{0x01, 0x9d, 0x9c, 0xfe}}
+	mtubVersions = hdVersions{[4]byte{0x01, 0xb2, 0x6e, 0xf6}, [4]byte{0x01, 0xb2, 0x67, 0x92}}
+	ttubVersions = hdVersions{[4]byte{0x04, 0x36, 0xf6, 0xe1}, [4]byte{0x04, 0x36, 0xef, 0x7d}}
+	// Dogecoin
+	dgubVersions = hdVersions{[4]byte{0x02, 0xfa, 0xca, 0xfd}, [4]byte{0x02, 0xfa, 0xc3, 0x98}}
+	tgubVersions = hdVersions{[4]byte{0x04, 0x32, 0xa9, 0xa8}, [4]byte{0x04, 0x32, 0xa2, 0x43}}
+)
@@ -0,0 +1,212 @@
+package hdwrap
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/Yawning/aez"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Aezeed (lnd cipher seed) parameters. An aezeed mnemonic encodes 33
+// bytes: version (1), ciphertext (19 + 4), salt (5) and checksum (4). The
+// ciphertext encrypts, with AEZ, the internal version (1), the birthday
+// (2) and the entropy (16).
+const (
+	aezeedVersion       = 0
+	aezeedWords         = 24
+	aezeedEntropySize   = 16
+	aezeedSaltSize      = 5
+	aezeedEncipheredLen = 33
+	aezeedExpansion     = 4
+	aezeedSaltOffset    = aezeedEncipheredLen - 4 - aezeedSaltSize
+	aezeedCRCOffset     = aezeedEncipheredLen - 4
+	aezeedScryptN       = 32768
+	aezeedScryptR       = 8
+	aezeedScryptP       = 1
+)
+
+// AezeedEntropySize is the size of the entropy encoded by aezeed
+// mnemonics, which is used directly as seed by lnd.
+const AezeedEntropySize = aezeedEntropySize
+
+// aezeedDefaultPassphrase is used when no passphrase is given.
+var aezeedDefaultPassphrase = "aezeed"
+
+// aezeedGenesis is the date of the Bitcoin genesis block. Aezeed
+// birthdays are days since then.
+var aezeedGenesis = time.Unix(1231006505, 0)
+
+var aezeedCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrWrongAezeedPassphrase is returned when an aezeed mnemonic cannot be
+// decrypted with the given passphrase.
+var ErrWrongAezeedPassphrase = errors.New("wrong aezeed passphrase")
+
+// An Aezeed holds the contents of an lnd aezeed cipher seed.
+type Aezeed struct {
+	// Version is the internal version of the seed, which defines how
+	// lnd derives keys from it.
+	Version uint8
+	// Birthday is the creation date of the seed (with day precision).
+	Birthday time.Time
+	Entropy  []byte
+}
+
+// EncodeAezeed encrypts the aezeed with the given passphrase (with a
+// random salt) and returns its 24-word mnemonic.
+func EncodeAezeed(a *Aezeed, passphrase string) ([]string, error) {
+	if len(a.Entropy) != aezeedEntropySize {
+		return nil, fmt.Errorf("aezeed entropy must be %d bytes", aezeedEntropySize)
+	}
+	if a.Birthday.Before(aezeedGenesis) {
+		return nil, fmt.Errorf("aezeed birthday is before the Bitcoin genesis block")
+	}
+
+	salt := make([]byte, aezeedSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	return encodeAezeed(a, passphrase, salt, aezeedScryptN, aezeedScryptR, aezeedScryptP)
+}
+
+// encodeAezeed encrypts the aezeed with the given passphrase, salt and
+// scrypt cost parameters and returns its mnemonic.
+func encodeAezeed(a *Aezeed, passphrase string, salt []byte, scryptN, scryptR, scryptP int) ([]string, error) {
+	key, err := aezeedKey(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	plaintext := make([]byte, 3, 3+aezeedEntropySize)
+	plaintext[0] = a.Version
+	birthday := a.Birthday.Sub(aezeedGenesis) / (24 * time.Hour)
+	binary.BigEndian.PutUint16(plaintext[1:], uint16(birthday))
+	plaintext = append(plaintext, a.Entropy...)
+	defer zero(plaintext)
+
+	enciphered := make([]byte, 0, aezeedEncipheredLen)
+	enciphered = append(enciphered, aezeedVersion)
+	enciphered = aez.Encrypt(key, nil, [][]byte{aezeedAD(salt)}, aezeedExpansion, plaintext, enciphered)
+	enciphered = append(enciphered, salt...)
+	crc := crc32.Checksum(enciphered, aezeedCRCTable)
+	enciphered = append(enciphered, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(enciphered[aezeedCRCOffset:], crc)
+
+	// The 33 bytes are split in 24 groups of 11 bits, which are
+	// BIP39 English word indexes.
+	wordlist := bip39Wordlists[English].words
+	n := new(big.Int).SetBytes(enciphered)
+	words := make([]string, aezeedWords)
+	for i := aezeedWords - 1; i >= 0; i-- {
+		words[i] = wordlist[new(big.Int).And(n, big.NewInt(2047)).Int64()]
+		n.Rsh(n, 11)
+	}
+	return words, nil
+}
+
+// DecodeAezeed decrypts an aezeed mnemonic with the given passphrase,
+// which may be empty.
+func DecodeAezeed(mnemonic, passphrase string) (*Aezeed, error) {
+	return decodeAezeed(mnemonic, passphrase, aezeedScryptN, aezeedScryptR, aezeedScryptP)
+}
+
+// decodeAezeed decrypts an aezeed mnemonic with the given passphrase and
+// scrypt cost parameters.
+func decodeAezeed(mnemonic, passphrase string, scryptN, scryptR, scryptP int) (*Aezeed, error) {
+	enciphered, err := aezeedEnciphered(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := enciphered[aezeedSaltOffset:aezeedCRCOffset]
+	key, err := aezeedKey(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	plaintext, ok := aez.Decrypt(key, nil, [][]byte{aezeedAD(salt)}, aezeedExpansion, enciphered[1:aezeedSaltOffset], nil)
+	if !ok {
+		return nil, ErrWrongAezeedPassphrase
+	}
+	defer zero(plaintext)
+
+	birthday := binary.BigEndian.Uint16(plaintext[1:3])
+	return &Aezeed{
+		Version:  plaintext[0],
+		Birthday: aezeedGenesis.Add(time.Duration(birthday) * 24 * time.Hour),
+		Entropy:  append([]byte{}, plaintext[3:]...),
+	}, nil
+}
+
+// NewAezeedSeedFile returns a SeedFile for an aezeed mnemonic, which is
+// decrypted with the given passphrase. As in lnd, the seed is the aezeed
+// entropy and the birthday is used as creation date.
+func NewAezeedSeedFile(mnemonic, passphrase string) (*SeedFile, error) {
+	a, err := DecodeAezeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if a.Version != 0 {
+		return nil, fmt.Errorf("unsupported aezeed internal version %d", a.Version)
+	}
+	return &SeedFile{
+		Scheme:  SchemeAezeed,
+		Seed:    a.Entropy,
+		Phrase:  strings.Join(bip39Words(mnemonic), " "),
+		Created: a.Birthday,
+	}, nil
+}
+
+// aezeedEnciphered returns the 33 bytes encoded by an aezeed mnemonic,
+// verifying their version and checksum.
+func aezeedEnciphered(mnemonic string) ([]byte, error) {
+	words := bip39Words(mnemonic)
+	if len(words) != aezeedWords {
+		return nil, fmt.Errorf("aezeed mnemonics have %d words", aezeedWords)
+	}
+
+	index := bip39Wordlists[English].index
+	n := new(big.Int)
+	for _, w := range words {
+		i, ok := index[w]
+		if !ok {
+			return nil, fmt.Errorf("unknown aezeed word: %s", w)
+		}
+		n.Lsh(n, 11)
+		n.Or(n, big.NewInt(int64(i)))
+	}
+	enciphered := paddedBytes(n, aezeedEncipheredLen)
+
+	if enciphered[0] != aezeedVersion {
+		return nil, fmt.Errorf("unsupported aezeed version %d", enciphered[0])
+	}
+	crc := crc32.Checksum(enciphered[:aezeedCRCOffset], aezeedCRCTable)
+	if crc != binary.BigEndian.Uint32(enciphered[aezeedCRCOffset:]) {
+		return nil, fmt.Errorf("bad aezeed checksum: the mnemonic is not valid")
+	}
+	return enciphered, nil
+}
+
+// aezeedKey derives the AEZ key from the passphrase with scrypt.
+func aezeedKey(passphrase string, salt []byte, scryptN, scryptR, scryptP int) ([]byte, error) {
+	if passphrase == "" {
+		passphrase = aezeedDefaultPassphrase
+	}
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+}
+
+// aezeedAD returns the additional data of the AEZ encryption: the
+// version followed by the salt.
+func aezeedAD(salt []byte) []byte {
+	return append([]byte{aezeedVersion}, salt...)
+}
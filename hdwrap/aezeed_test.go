@@ -0,0 +1,103 @@
+package hdwrap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Scrypt cost parameters of the lnd test vectors.
+const (
+	aezeedTestScryptN = 16
+	aezeedTestScryptR = 8
+	aezeedTestScryptP = 1
+)
+
+// Version 0 test vectors from lnd (aezeed/cipherseed_test.go).
+var aezeedVectors = []struct {
+	birthday   time.Time
+	passphrase string
+	mnemonic   string
+	days       int
+}{
+	{
+		aezeedGenesis, "",
+		"ability liquid travel stem barely drastic pact cupboard apple thrive morning oak feature tissue couch old math inform success suggest drink motion know royal",
+		0,
+	},
+	{
+		time.Unix(1521799345, 0), "!very_safe_55345_password*",
+		"able tree stool crush transfer cloud cross three profit outside hen citizen plate ride require leg siren drum success suggest drink require fiscal upgrade",
+		3365,
+	},
+}
+
+var aezeedTestEntropy = []byte{
+	0x81, 0xb6, 0x37, 0xd8, 0x63, 0x59, 0xe6, 0x96,
+	0x0d, 0xe7, 0x95, 0xe4, 0x1e, 0x0b, 0x4c, 0xfd,
+}
+
+func TestAezeedVectors(t *testing.T) {
+	for _, v := range aezeedVectors {
+		a := &Aezeed{Birthday: v.birthday, Entropy: aezeedTestEntropy}
+		words, err := encodeAezeed(a, v.passphrase, []byte("salt1"), aezeedTestScryptN, aezeedTestScryptR, aezeedTestScryptP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(words, " "); got != v.mnemonic {
+			t.Errorf("mnemonic %s, expected %s", got, v.mnemonic)
+		}
+
+		decoded, err := decodeAezeed(v.mnemonic, v.passphrase, aezeedTestScryptN, aezeedTestScryptR, aezeedTestScryptP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Version != 0 {
+			t.Errorf("version %d, expected 0", decoded.Version)
+		}
+		if !bytes.Equal(decoded.Entropy, aezeedTestEntropy) {
+			t.Errorf("entropy %x, expected %x", decoded.Entropy, aezeedTestEntropy)
+		}
+		if days := int(decoded.Birthday.Sub(aezeedGenesis) / (24 * time.Hour)); days != v.days {
+			t.Errorf("birthday %d, expected %d", days, v.days)
+		}
+	}
+}
+
+func TestAezeedErrors(t *testing.T) {
+	v := aezeedVectors[1]
+	if _, err := decodeAezeed(v.mnemonic, "wrong", aezeedTestScryptN, aezeedTestScryptR, aezeedTestScryptP); err != ErrWrongAezeedPassphrase {
+		t.Errorf("expected ErrWrongAezeedPassphrase, got %v", err)
+	}
+
+	words := strings.Fields(v.mnemonic)
+	words[3] = "zoo"
+	if _, err := decodeAezeed(strings.Join(words, " "), v.passphrase, aezeedTestScryptN, aezeedTestScryptR, aezeedTestScryptP); err == nil || err == ErrWrongAezeedPassphrase {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+
+	if _, err := DecodeAezeed(strings.Join(words[:12], " "), v.passphrase); err == nil {
+		t.Error("expected an error for 12 words")
+	}
+}
+
+func TestAezeedRoundTrip(t *testing.T) {
+	a := &Aezeed{Birthday: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), Entropy: aezeedTestEntropy}
+	words, err := EncodeAezeed(a, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err := NewAezeedSeedFile(strings.Join(words, " "), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sf.Seed, aezeedTestEntropy) {
+		t.Errorf("seed %x, expected %x", sf.Seed, aezeedTestEntropy)
+	}
+	// Birthdays have day precision.
+	days := a.Birthday.Sub(aezeedGenesis) / (24 * time.Hour)
+	if created := aezeedGenesis.Add(days * 24 * time.Hour); !sf.Created.Equal(created) {
+		t.Errorf("created %s, expected %s", sf.Created, created)
+	}
+}
@@ -0,0 +1,144 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Const is the constant XORed into the checksum by the
+// original bech32 encoding (BIP173).
+const bech32Const = 1
+
+// bech32mConst is the checksum constant of the bech32m encoding
+// (BIP350), used for segwit version 1 and later.
+const bech32mConst = 0x2bc830a3
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	exp := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		exp = append(exp, hrp[i]>>5)
+	}
+	exp = append(exp, 0)
+	for i := 0; i < len(hrp); i++ {
+		exp = append(exp, hrp[i]&31)
+	}
+	return exp
+}
+
+// bech32Encode encodes the given 5-bit groups with the given
+// human-readable part. The checksum constant selects the variant.
+// No length limits are enforced, so that it can be used with formats
+// other than segwit addresses.
+func bech32Encode(hrp string, data []byte, checksumConst uint32) string {
+	hrp = strings.ToLower(hrp)
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ checksumConst
+
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteString("1")
+	for _, d := range data {
+		b.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		b.WriteByte(bech32Charset[(mod>>uint(5*(5-i)))&31])
+	}
+	return b.String()
+}
+
+// bech32EncodeBytes encodes bytes with bech32 (BIP173 checksum), as
+// used by Cardano and Cosmos addresses and keys.
+func bech32EncodeBytes(hrp string, b []byte) string {
+	// Conversion from 8-bit values cannot fail.
+	data, _ := convertBits(b, 8, 5, true)
+	return bech32Encode(hrp, data, bech32Const)
+}
+
+// bech32Decode decodes a bech32 string (BIP173 checksum) into its
+// human-readable part and 5-bit groups. As bech32Encode, it enforces no
+// length limits.
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("bech32 strings cannot mix upper and lower case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, fmt.Errorf("invalid bech32 string")
+	}
+	hrp := s[:sep]
+	data := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if d < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character: %c", s[i])
+		}
+		data = append(data, byte(d))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != bech32Const {
+		return "", nil, fmt.Errorf("bad bech32 checksum")
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups a slice of fromBits-wide values into
+// toBits-wide values.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	acc := uint32(0)
+	bits := uint(0)
+	maxv := uint32(1)<<toBits - 1
+	var out []byte
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data range: %d", v)
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return out, nil
+}
+
+// encodeSegwitAddress returns the bech32 encoding of a segwit
+// output with the given witness version and program (BIP173).
+// Version 1+ outputs use bech32m (BIP350).
+func encodeSegwitAddress(hrp string, version byte, program []byte) (string, error) {
+	data, err := convertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksumConst := uint32(bech32Const)
+	if version > 0 {
+		checksumConst = bech32mConst
+	}
+	return bech32Encode(hrp, append([]byte{version}, data...), checksumConst), nil
+}
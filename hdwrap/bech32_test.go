@@ -0,0 +1,119 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// bech32Verify returns true when s is a valid bech32 string for the
+// given checksum constant (bech32 or bech32m).
+func bech32Verify(s string, checksumConst uint32) bool {
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return false
+	}
+	data := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		d := strings.IndexByte(bech32Charset, s[i])
+		if d < 0 {
+			return false
+		}
+		data = append(data, byte(d))
+	}
+	return bech32Polymod(append(bech32HRPExpand(s[:sep]), data...)) == checksumConst
+}
+
+// Valid checksums from BIP173 (bech32) and BIP350 (bech32m). Each of
+// them is invalid with the other constant.
+func TestBech32Checksums(t *testing.T) {
+	bech32 := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqc8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	}
+	bech32m := []string{
+		"A1LQFN3A",
+		"a1lqfn3a",
+		"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6",
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx",
+		"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8",
+		"split1checkupstagehandshakeupstreamerranterredcaperredlc445v",
+		"?1v759aa",
+	}
+	for _, s := range bech32 {
+		if !bech32Verify(s, bech32Const) || bech32Verify(s, bech32mConst) {
+			t.Errorf("%s: expected a valid bech32 checksum", s)
+		}
+	}
+	for _, s := range bech32m {
+		if !bech32Verify(s, bech32mConst) || bech32Verify(s, bech32Const) {
+			t.Errorf("%s: expected a valid bech32m checksum", s)
+		}
+	}
+}
+
+func TestBech32Decode(t *testing.T) {
+	hrp, data, err := bech32Decode("abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrp != "abcdef" || len(data) != 32 {
+		t.Errorf("decoded %s with %d values", hrp, len(data))
+	}
+	for i, d := range data {
+		if int(d) != i {
+			t.Errorf("value %d is %d", i, d)
+		}
+	}
+
+	for _, s := range []string{
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", // checksum
+		"A1LQFN3A",      // bech32m
+		"a12UEL5L",      // mixed case
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty hrp
+	} {
+		if _, _, err := bech32Decode(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+// Segwit address test vectors from BIP173 (version 0) and BIP350
+// (version 1+).
+func TestEncodeSegwitAddress(t *testing.T) {
+	tests := []struct {
+		hrp     string
+		version byte
+		program string
+		address string
+	}{
+		{"bc", 0, "751e76e8199196d454941c45d1b3a323f1433bd6", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"tb", 0, "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"},
+		{"tb", 0, "000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433", "tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy"},
+		{"bc", 1, "751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6", "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y"},
+		{"bc", 16, "751e", "bc1sw50qgdz25j"},
+		{"bc", 2, "751e76e8199196d454941c45d1b3a323", "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs"},
+		{"tb", 1, "000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433", "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c"},
+		{"bc", 1, "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+	}
+	for _, tc := range tests {
+		program, err := hex.DecodeString(tc.program)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := encodeSegwitAddress(tc.hrp, tc.version, program)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != tc.address {
+			t.Errorf("version %d: %s, expected %s", tc.version, addr, tc.address)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package hdwrap
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// A BIP32Ed25519Key is an extended ed25519 key derived following
+// BIP32-Ed25519 (Khovratovich and Law), as used by Cardano wallets.
+// Unlike SLIP-10, it supports non-hardened derivation, so that public
+// keys can derive children. Private keys hold the 64-byte extended
+// secret (kL, kR), public keys only the point A = kL*B.
+type BIP32Ed25519Key struct {
+	kL        []byte
+	kR        []byte
+	pub       []byte
+	chainCode []byte
+}
+
+// NewIcarusMaster derives the Cardano Icarus master key from the BIP39
+// entropy and passphrase (CIP-3): PBKDF2-HMAC-SHA512 with the passphrase
+// as password and the entropy as salt, with the result clamped as an
+// ed25519 secret.
+func NewIcarusMaster(entropy []byte, passphrase string) *BIP32Ed25519Key {
+	data := pbkdf2.Key([]byte(passphrase), entropy, 4096, 96, sha512.New)
+	data[0] &= 0xf8
+	data[31] &= 0x1f
+	data[31] |= 0x40
+	return newBIP32Ed25519Key(data[:32], data[32:64], data[64:])
+}
+
+func newBIP32Ed25519Key(kL, kR, chainCode []byte) *BIP32Ed25519Key {
+	return &BIP32Ed25519Key{
+		kL:        kL,
+		kR:        kR,
+		pub:       ed25519ScalarBaseMult(kL).Bytes(),
+		chainCode: chainCode,
+	}
+}
+
+// parseBIP32Ed25519Key parses the 96 bytes of an extended private key
+// (kL, kR, chain code) or the 64 bytes of an extended public key (A,
+// chain code).
+func parseBIP32Ed25519Key(b []byte) (*BIP32Ed25519Key, error) {
+	switch len(b) {
+	case 96:
+		if b[0]&0x07 != 0 {
+			return nil, fmt.Errorf("invalid BIP32-Ed25519 private key")
+		}
+		return newBIP32Ed25519Key(b[:32], b[32:64], b[64:]), nil
+	case 64:
+		if _, err := new(edwards25519.Point).SetBytes(b[:32]); err != nil {
+			return nil, fmt.Errorf("invalid BIP32-Ed25519 public key")
+		}
+		return &BIP32Ed25519Key{pub: b[:32], chainCode: b[32:]}, nil
+	default:
+		return nil, fmt.Errorf("BIP32-Ed25519 extended keys are 64 (public) or 96 (private) bytes")
+	}
+}
+
+// IsPrivate returns true for private keys.
+func (k *BIP32Ed25519Key) IsPrivate() bool {
+	return k.kL != nil
+}
+
+// Neuter returns the public key corresponding to this key.
+func (k *BIP32Ed25519Key) Neuter() *BIP32Ed25519Key {
+	return &BIP32Ed25519Key{pub: k.pub, chainCode: k.chainCode}
+}
+
+// Child derives the child key with the given index (derivation scheme
+// V2). Indexes >= HardenedKeyStart are hardened and can only be derived
+// from private keys.
+func (k *BIP32Ed25519Key) Child(i uint32) (*BIP32Ed25519Key, error) {
+	hardened := i >= HardenedKeyStart
+	if hardened && !k.IsPrivate() {
+		return nil, hdkeychain.ErrDeriveHardFromPublic
+	}
+
+	index := make([]byte, 4)
+	binary.LittleEndian.PutUint32(index, i)
+	var zData, ccData []byte
+	if hardened {
+		zData = append(append([]byte{0x00}, k.kL...), k.kR...)
+		ccData = append(append([]byte{0x01}, k.kL...), k.kR...)
+	} else {
+		zData = append([]byte{0x02}, k.pub...)
+		ccData = append([]byte{0x03}, k.pub...)
+	}
+	z := bip32Ed25519HMAC(k.chainCode, zData, index)
+	chainCode := bip32Ed25519HMAC(k.chainCode, ccData, index)[32:]
+
+	// 8*ZL, ZL being the first 28 bytes of Z.
+	zl8 := new(big.Int).Lsh(leToInt(z[:28]), 3)
+
+	if !k.IsPrivate() {
+		point, err := new(edwards25519.Point).SetBytes(k.pub)
+		if err != nil {
+			return nil, err
+		}
+		pub := new(edwards25519.Point).Add(point, ed25519ScalarBaseMult(intToLE(zl8, 32)))
+		return &BIP32Ed25519Key{pub: pub.Bytes(), chainCode: chainCode}, nil
+	}
+
+	// kL = 8*ZL + kL and kR = ZR + kR (mod 2^256).
+	mod := new(big.Int).Lsh(big.NewInt(1), 256)
+	kL := new(big.Int).Add(zl8, leToInt(k.kL))
+	if kL.Cmp(mod) >= 0 {
+		return nil, fmt.Errorf("derived BIP32-Ed25519 key is invalid")
+	}
+	kR := new(big.Int).Add(leToInt(z[32:]), leToInt(k.kR))
+	kR.Mod(kR, mod)
+	return newBIP32Ed25519Key(intToLE(kL, 32), intToLE(kR, 32), chainCode), nil
+}
+
+// Derive walks the given path starting at this key and returns the
+// resulting key.
+func (k *BIP32Ed25519Key) Derive(p Path) (*BIP32Ed25519Key, error) {
+	key := k
+	for _, i := range p {
+		child, err := key.Child(i)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// PublicKey returns the 32-byte ed25519 public key.
+func (k *BIP32Ed25519Key) PublicKey() []byte {
+	return append([]byte{}, k.pub...)
+}
+
+// ChainCode returns the chain code of the key.
+func (k *BIP32Ed25519Key) ChainCode() []byte {
+	return append([]byte{}, k.chainCode...)
+}
+
+// Bytes returns the serialization used by Cardano tools: the extended
+// secret followed by the chain code (96 bytes) for private keys, and the
+// public key followed by the chain code (64 bytes) for public keys.
+func (k *BIP32Ed25519Key) Bytes() []byte {
+	if k.IsPrivate() {
+		return append(append(append([]byte{}, k.kL...), k.kR...), k.chainCode...)
+	}
+	return append(append([]byte{}, k.pub...), k.chainCode...)
+}
+
+func bip32Ed25519HMAC(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// ed25519ScalarBaseMult returns the point s*B, s being a little-endian
+// 32-byte scalar which is not clamped nor hashed.
+func ed25519ScalarBaseMult(s []byte) *edwards25519.Point {
+	wide := make([]byte, 64)
+	copy(wide, s)
+	scalar, err := new(edwards25519.Scalar).SetUniformBytes(wide)
+	if err != nil {
+		panic(err)
+	}
+	return new(edwards25519.Point).ScalarBaseMult(scalar)
+}
+
+// leToInt returns the value of a little-endian number.
+func leToInt(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// intToLE returns the little-endian encoding of n in size bytes.
+func intToLE(n *big.Int, size int) []byte {
+	b := paddedBytes(n, size)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
@@ -0,0 +1,80 @@
+package hdwrap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// Icarus master key test vectors (CIP-3).
+var icarusVectors = []struct {
+	entropy    string
+	passphrase string
+	master     string
+}{
+	{
+		"46e62370a138a182a498b8e2885bc032379ddf38", "",
+		"c065afd2832cd8b087c4d9ab7011f481ee1e0721e78ea5dd609f3ab3f156d245d176bd8fd4ec60b4731c3918a2a72a0226c0cd119ec35b47e4d55884667f552a23f7fdcd4a10c6cd2c7393ac61d877873e248f417634aa3d812af327ffe9d620",
+	},
+	{
+		"46e62370a138a182a498b8e2885bc032379ddf38", "foo",
+		"70531039904019351e1afb361cd1b312a4d0565d4ff9f8062d38acf4b15cce41d7b5738d9c893feea55512a3004acb0d222c35d3e3d5cde943a15a9824cbac59443cf67e589614076ba01e354b1a432e0e6db3b59e37fc56b5fb0222970a010e",
+	},
+}
+
+func TestIcarusMaster(t *testing.T) {
+	for _, v := range icarusVectors {
+		entropy, err := hex.DecodeString(v.entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		master := NewIcarusMaster(entropy, v.passphrase)
+		if got := hex.EncodeToString(master.Bytes()); got != v.master {
+			t.Errorf("passphrase %q: master key %s, expected %s", v.passphrase, got, v.master)
+		}
+
+		parsed, err := parseBIP32Ed25519Key(master.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(parsed.PublicKey(), master.PublicKey()) {
+			t.Error("parsed key has a different public key")
+		}
+	}
+}
+
+func TestBIP32Ed25519Derivation(t *testing.T) {
+	entropy, _ := hex.DecodeString(icarusVectors[0].entropy)
+	master := NewIcarusMaster(entropy, "")
+	account, err := master.Derive(Path{Hardened(1852), Hardened(1815), Hardened(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-hardened children of the public key are the public keys of
+	// the children of the private key.
+	accountPub := account.Neuter()
+	for _, p := range []Path{{0, 0}, {0, 7}, {1, 3}, {2, 0}} {
+		priv, err := account.Derive(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := accountPub.Derive(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(priv.Neuter().Bytes(), pub.Bytes()) {
+			t.Errorf("%s: public derivation does not match", p)
+		}
+	}
+
+	if _, err := accountPub.Child(Hardened(0)); err != hdkeychain.ErrDeriveHardFromPublic {
+		t.Errorf("expected ErrDeriveHardFromPublic, got %v", err)
+	}
+
+	if _, err := parseBIP32Ed25519Key(make([]byte, 32)); err == nil {
+		t.Error("expected an error for a 32-byte key")
+	}
+}
@@ -0,0 +1,146 @@
+package hdwrap
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// BIP39 mnemonics can have 12, 15, 18, 21 or 24 words, encoding
+// 128 to 256 bits of entropy plus a checksum.
+var bip39WordCounts = map[int]int{
+	12: 16,
+	15: 20,
+	18: 24,
+	21: 28,
+	24: 32,
+}
+
+// BIP39EntropyLen returns the number of entropy bytes encoded by a BIP39
+// mnemonic with the given number of words.
+func BIP39EntropyLen(words int) (int, error) {
+	l, ok := bip39WordCounts[words]
+	if !ok {
+		return 0, fmt.Errorf("BIP39 mnemonics must have 12, 15, 18, 21 or 24 words")
+	}
+	return l, nil
+}
+
+// EncodeBIP39 encodes entropy (16 to 32 bytes, in multiples of 4) as a
+// BIP39 mnemonic list of words in the given language.
+func EncodeBIP39(entropy []byte, lang Language) ([]string, error) {
+	wl, ok := bip39Wordlists[lang]
+	if !ok {
+		return nil, fmt.Errorf("no BIP39 wordlist for language %d", lang)
+	}
+
+	entBits := len(entropy) * 8
+	if entBits < 128 || entBits > 256 || entBits%32 != 0 {
+		return nil, fmt.Errorf("BIP39 entropy must be 128 to 256 bits long, in multiples of 32")
+	}
+	csBits := entBits / 32
+
+	// entropy || first csBits of sha256(entropy), split in 11-bit groups
+	h := sha256.Sum256(entropy)
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, uint(csBits))
+	data.Or(data, big.NewInt(int64(h[0]>>uint(8-csBits))))
+
+	nWords := (entBits + csBits) / 11
+	words := make([]string, nWords)
+	mask := big.NewInt(2047)
+	idx := new(big.Int)
+	for i := nWords - 1; i >= 0; i-- {
+		idx.And(data, mask)
+		words[i] = wl.words[idx.Int64()]
+		data.Rsh(data, 11)
+	}
+	return words, nil
+}
+
+// DecodeBIP39 validates a BIP39 mnemonic in the given language (given as
+// a space-separated list of words) and returns the entropy it encodes.
+func DecodeBIP39(mnemonic string, lang Language) ([]byte, error) {
+	wl, ok := bip39Wordlists[lang]
+	if !ok {
+		return nil, fmt.Errorf("no BIP39 wordlist for language %d", lang)
+	}
+
+	words := bip39Words(mnemonic)
+	entLen, err := BIP39EntropyLen(len(words))
+	if err != nil {
+		return nil, err
+	}
+
+	data := new(big.Int)
+	for _, w := range words {
+		i, ok := wl.index[w]
+		if !ok {
+			return nil, fmt.Errorf("word %q is not in the %s BIP39 wordlist", w, lang)
+		}
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(i)))
+	}
+
+	csBits := uint(entLen / 4)
+	checksum := byte(new(big.Int).And(data, big.NewInt(1<<csBits-1)).Int64())
+	data.Rsh(data, csBits)
+	entropy := paddedBytes(data, entLen)
+
+	h := sha256.Sum256(entropy)
+	if h[0]>>(8-csBits) != checksum {
+		return nil, fmt.Errorf("invalid BIP39 mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// DetectBIP39Language returns the language of a BIP39 mnemonic: the
+// one whose wordlist contains all the words and for which the checksum
+// is valid. An error is returned when there is no such language or when
+// several languages match.
+func DetectBIP39Language(mnemonic string) (Language, error) {
+	var matches []Language
+	var names []string
+	for l := English; l <= Portuguese; l++ {
+		if _, err := DecodeBIP39(mnemonic, l); err == nil {
+			matches = append(matches, l)
+			names = append(names, l.String())
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("not a valid BIP39 mnemonic in any supported language")
+	case 1:
+		return matches[0], nil
+	default:
+		return 0, fmt.Errorf("mnemonic is valid in several languages (%s). Please specify one", strings.Join(names, ", "))
+	}
+}
+
+// NewSeedFromBIP39 validates a BIP39 mnemonic, detecting its language,
+// and derives the 512-bit seed from it and the optional passphrase,
+// using PBKDF2-HMAC-SHA512 as specified by BIP39.
+func NewSeedFromBIP39(mnemonic, passphrase string) (Seed, error) {
+	if _, err := DetectBIP39Language(mnemonic); err != nil {
+		return nil, err
+	}
+	return bip39Seed(strings.Join(bip39Words(mnemonic), " "), passphrase), nil
+}
+
+// bip39Words splits a mnemonic into NFKD-normalized, lowercase words.
+// Any whitespace, including the ideographic space used by Japanese
+// mnemonics, separates words.
+func bip39Words(mnemonic string) []string {
+	return strings.Fields(strings.ToLower(normalizeNFKD(mnemonic)))
+}
+
+func bip39Seed(mnemonic, passphrase string) Seed {
+	mnemonic = normalizeNFKD(mnemonic)
+	passphrase = normalizeNFKD(passphrase)
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
@@ -0,0 +1,205 @@
+package hdwrap
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// BIP85Purpose is the purpose path element of BIP85 derivation paths.
+const BIP85Purpose uint32 = 83696968
+
+// BIP85 application numbers
+const (
+	BIP85AppBIP39 uint32 = 39
+	BIP85AppWIF   uint32 = 2
+	BIP85AppXPRV  uint32 = 32
+	BIP85AppHex   uint32 = 128169
+	// Password applications
+	BIP85AppPWDBase64 uint32 = 707764
+	BIP85AppPWDBase85 uint32 = 707785
+)
+
+// bip85HMACKey is the HMAC-SHA512 key used to derive entropy from the
+// private key of BIP85 derived keys.
+var bip85HMACKey = []byte("bip-entropy-from-k")
+
+// bip85LanguageCodes are the BIP85 codes of the BIP39 wordlist
+// languages.
+var bip85LanguageCodes = map[Language]uint32{
+	English:            0,
+	Japanese:           1,
+	Korean:             2,
+	Spanish:            3,
+	ChineseSimplified:  4,
+	ChineseTraditional: 5,
+	French:             6,
+	Italian:            7,
+	Czech:              8,
+	Portuguese:         9,
+}
+
+// BIP85Entropy derives 64 bytes of entropy from the seed, using the
+// BIP85 derivation path m/83696968'/<p...>. All the elements of p must
+// be hardened.
+func (s Seed) BIP85Entropy(p Path) ([]byte, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	return bip85Entropy(master, p)
+}
+
+// BIP85BIP39 derives the entropy of a child BIP39 mnemonic with the
+// given number of words and language. It can be encoded with
+// EncodeBIP39.
+func (s Seed) BIP85BIP39(lang Language, words int, index uint32) ([]byte, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	return bip85BIP39(master, lang, words, index)
+}
+
+// BIP85WIF derives a child private key and returns it in Wallet
+// Import Format for the given Bitcoin network.
+func (s Seed) BIP85WIF(index uint32, n Network) (string, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return "", err
+	}
+	return bip85WIF(master, index, n)
+}
+
+// BIP85XPRV derives a child master extended private key (xprv..., or
+// tprv... for test networks).
+func (s Seed) BIP85XPRV(index uint32, n Network) (string, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return "", err
+	}
+	return bip85XPRV(master, index, n)
+}
+
+// BIP85Hex derives numBytes (16 to 64) bytes of child entropy.
+func (s Seed) BIP85Hex(numBytes int, index uint32) ([]byte, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	return bip85Hex(master, numBytes, index)
+}
+
+func bip85BIP39(master *hdkeychain.ExtendedKey, lang Language, words int, index uint32) ([]byte, error) {
+	entLen, err := BIP39EntropyLen(words)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := bip85LanguageCodes[lang]
+	if !ok {
+		return nil, fmt.Errorf("language %s is not supported by BIP85", lang)
+	}
+	entropy, err := bip85Entropy(master, Path{
+		Hardened(BIP85AppBIP39),
+		Hardened(code),
+		Hardened(uint32(words)),
+		Hardened(index),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entropy[:entLen], nil
+}
+
+func bip85WIF(master *hdkeychain.ExtendedKey, index uint32, n Network) (string, error) {
+	params, ok := bitcoinNetworks[n]
+	if !ok {
+		return "", ErrUnsupportedNetwork{KeyType: Btc, Network: n}
+	}
+	entropy, err := bip85Entropy(master, Path{Hardened(BIP85AppWIF), Hardened(index)})
+	if err != nil {
+		return "", err
+	}
+	if !validPrivKey(entropy[:32]) {
+		return "", fmt.Errorf("derived private key is invalid. Use a different index")
+	}
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), entropy[:32])
+	return encodeWIF(priv, params.privateKeyID), nil
+}
+
+func bip85XPRV(master *hdkeychain.ExtendedKey, index uint32, n Network) (string, error) {
+	params, ok := bitcoinNetworks[n]
+	if !ok {
+		return "", ErrUnsupportedNetwork{KeyType: Btc, Network: n}
+	}
+	entropy, err := bip85Entropy(master, Path{Hardened(BIP85AppXPRV), Hardened(index)})
+	if err != nil {
+		return "", err
+	}
+	// The chain code comes first, followed by the private key.
+	chainCode, key := entropy[:32], entropy[32:]
+	if !validPrivKey(key) {
+		return "", fmt.Errorf("derived private key is invalid. Use a different index")
+	}
+	versions := params.hdVersions[P2PKH]
+	xprv := hdkeychain.NewExtendedKey(versions.priv[:], key, chainCode, []byte{0, 0, 0, 0}, 0, 0, true)
+	return xprv.String(), nil
+}
+
+func bip85Hex(master *hdkeychain.ExtendedKey, numBytes int, index uint32) ([]byte, error) {
+	if numBytes < 16 || numBytes > 64 {
+		return nil, fmt.Errorf("the number of bytes must be between 16 and 64")
+	}
+	entropy, err := bip85Entropy(master, Path{
+		Hardened(BIP85AppHex),
+		Hardened(uint32(numBytes)),
+		Hardened(index),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entropy[:numBytes], nil
+}
+
+// bip85Entropy derives the key at m/83696968'/<p...> from the given
+// master key and returns HMAC-SHA512("bip-entropy-from-k", k), k being
+// its private key. Short private keys are padded when deriving hardened
+// children, as BIP32 requires, so that the results match other BIP85
+// implementations.
+func bip85Entropy(master *hdkeychain.ExtendedKey, p Path) ([]byte, error) {
+	key, err := master.Derive(Hardened(BIP85Purpose))
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range p {
+		if i < HardenedKeyStart {
+			return nil, fmt.Errorf("BIP85 derivation paths must be fully hardened")
+		}
+		key, err = key.Derive(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	k := paddedBytes(priv.D, btcec.PrivKeyBytesLen)
+	defer zero(k)
+
+	mac := hmac.New(sha512.New, bip85HMACKey)
+	mac.Write(k)
+	return mac.Sum(nil), nil
+}
+
+// validPrivKey returns true when the given bytes are a valid secp256k1
+// private key (not zero and lower than the curve order).
+func validPrivKey(b []byte) bool {
+	d := new(big.Int).SetBytes(b)
+	return d.Sign() > 0 && d.Cmp(btcec.S256().N) < 0
+}
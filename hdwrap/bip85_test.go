@@ -0,0 +1,175 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// bip85Master is the master key of the BIP85 test vectors
+// (https://github.com/bitcoin/bips/blob/master/bip-0085.mediawiki).
+const bip85Master = "xprv9s21ZrQH143K2LBWUUQRFXhucrQqBpKdRRxNVq2zBqsx8HVqFk2uYo8kmbaLLHRdqtQpUm98uKfu3vca1LqdGhUtyoFnCNkfmXRyPXLjbKb"
+
+func bip85TestMaster(t *testing.T) *hdkeychain.ExtendedKey {
+	t.Helper()
+	master, err := hdkeychain.NewKeyFromString(bip85Master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return master
+}
+
+func TestBIP85Entropy(t *testing.T) {
+	master := bip85TestMaster(t)
+	tests := []struct {
+		path    string
+		entropy string
+	}{
+		{"0'/0'", "efecfbccffea313214232d29e71563d941229afb4338c21f9517c41aaa0d16f00b83d2a09ef747e7a64e8e2bd5a14869e693da66ce94ac2da570ab7ee48618f7"},
+		{"0'/1'", "70c6e3e8ebee8dc4c0dbba66076819bb8c09672527c4277ca8729532ad711872218f826919f6b67218adde99018a6df9095ab2b58d803b5b93ec9802085a690e"},
+		// The private key at m/83696968'/707785'/61' is shorter than
+		// 32 bytes and must be padded to derive its hardened children.
+		{"707785'/61'/0'", "910419a59575101beede4501fa3d257992fe453eafb3915a54b2912fc865d1eef43e77cb909fd4cf7d80ebf3506944e67b6b072cfc01d8245951b15b476bc180"},
+	}
+	for _, tc := range tests {
+		p, err := ParsePath(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entropy, err := bip85Entropy(master, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(entropy); got != tc.entropy {
+			t.Errorf("%s: entropy %s, expected %s", tc.path, got, tc.entropy)
+		}
+	}
+
+	if _, err := bip85Entropy(master, Path{Hardened(0), 0}); err == nil {
+		t.Error("expected an error for a non-hardened path")
+	}
+}
+
+func TestBIP85BIP39(t *testing.T) {
+	master := bip85TestMaster(t)
+	tests := []struct {
+		words    int
+		mnemonic string
+	}{
+		{12, "girl mad pet galaxy egg matter matrix prison refuse sense ordinary nose"},
+		{18, "near account window bike charge season chef number sketch tomorrow excuse sniff circle vital hockey outdoor supply token"},
+		{24, "puppy ocean match cereal symbol another shed magic wrap hammer bulb intact gadget divorce twin tonight reason outdoor destroy simple truth cigar social volcano"},
+	}
+	for _, tc := range tests {
+		entropy, err := bip85BIP39(master, English, tc.words, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		words, err := EncodeBIP39(entropy, English)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(words, " "); got != tc.mnemonic {
+			t.Errorf("%d words: %s, expected %s", tc.words, got, tc.mnemonic)
+		}
+	}
+
+	if _, err := bip85BIP39(master, English, 13, 0); err == nil {
+		t.Error("expected an error for 13 words")
+	}
+}
+
+func TestBIP85WIF(t *testing.T) {
+	wif, err := bip85WIF(bip85TestMaster(t), 0, Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Kzyv4uF39d4Jrw2W7UryTHwZr1zQVNk4dAFyqE6BuMrMh1Za7uhp"; wif != expected {
+		t.Errorf("WIF %s, expected %s", wif, expected)
+	}
+}
+
+func TestBIP85XPRV(t *testing.T) {
+	xprv, err := bip85XPRV(bip85TestMaster(t), 0, Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "xprv9s21ZrQH143K2srSbCSg4m4kLvPMzcWydgmKEnMmoZUurYuBuYG46c6P71UGXMzmriLzCCBvKQWBUv3vPB3m1SATMhp3uEjXHJ42jFg7myX"; xprv != expected {
+		t.Errorf("XPRV %s, expected %s", xprv, expected)
+	}
+}
+
+func TestBIP85Hex(t *testing.T) {
+	master := bip85TestMaster(t)
+	entropy, err := bip85Hex(master, 64, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "492db4698cf3b73a5a24998aa3e9d7fa96275d85724a91e71aa2d645442f878555d078fd1f1f67e368976f04137b1f7a0d19232136ca50c44614af72b5582a5c"
+	if got := hex.EncodeToString(entropy); got != expected {
+		t.Errorf("HEX %s, expected %s", got, expected)
+	}
+
+	for _, n := range []int{15, 65} {
+		if _, err := bip85Hex(master, n, 0); err == nil {
+			t.Errorf("expected an error for %d bytes", n)
+		}
+	}
+}
+
+func TestBIP85Password(t *testing.T) {
+	master := bip85TestMaster(t)
+	tests := []struct {
+		format   PasswordFormat
+		length   int
+		password string
+	}{
+		{PasswordBase64, 21, "dKLoepugzdVJvdL56ogNV"},
+		{PasswordBase85, 12, "_s`{TW89)i4`"},
+		// Derived from a short private key (see TestBIP85Entropy).
+		{PasswordBase85, 61, "kpvl~m30ss?%qWK`aLCilKw?Lud|U_RI-sT$Ys&)^gegXke}4geSqupP-#Twd"},
+	}
+	for _, tc := range tests {
+		pwd, err := bip85Password(master, tc.format, tc.length, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pwd != tc.password {
+			t.Errorf("%s %d: password %s, expected %s", tc.format, tc.length, pwd, tc.password)
+		}
+	}
+
+	for _, tc := range []struct {
+		format PasswordFormat
+		length int
+	}{
+		{PasswordBase64, 19},
+		{PasswordBase64, 87},
+		{PasswordBase85, 9},
+		{PasswordBase85, 81},
+	} {
+		if _, err := bip85Password(master, tc.format, tc.length, 0); err == nil {
+			t.Errorf("expected an error for %s passwords of length %d", tc.format, tc.length)
+		}
+	}
+}
+
+func TestSitePasswordIndex(t *testing.T) {
+	tests := []struct {
+		site    string
+		counter uint32
+		index   uint32
+	}{
+		{"example.com", 0, 405367733},
+		{"example.com", 1, 411445414},
+		{"github.com", 0, 733469337},
+		{"github.com", 1, 333091488},
+	}
+	for _, tc := range tests {
+		if got := SitePasswordIndex(tc.site, tc.counter); got != tc.index {
+			t.Errorf("%s/%d: index %d, expected %d", tc.site, tc.counter, got, tc.index)
+		}
+	}
+}
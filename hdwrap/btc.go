@@ -1,6 +1,7 @@
 package hdwrap
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 
@@ -8,7 +9,6 @@ import (
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/btcsuite/btcutil/hdkeychain"
 )
@@ -16,23 +16,82 @@ import (
 // BtcKey implements the Key interface for a Bitcoin
 // HDWallet.
 type BtcKey struct {
-	key     *hdkeychain.ExtendedKey
-	testnet bool
+	key      *hdkeychain.ExtendedKey
+	net      Network
+	addrType AddressType
+	// electrum is set for keys of Electrum wallets, which use their
+	// own derivation paths instead of BIP44 ones.
+	electrum ElectrumSeedType
+	// networks provides the encoding parameters for each supported
+	// network. It defaults to bitcoinNetworks when nil.
+	networks map[Network]*btcParams
 }
 
+// btcAddressTypes lists the address types in the order used to infer
+// them from extended key versions.
+var btcAddressTypes = []AddressType{P2PKH, P2SHP2WPKH, P2WPKH, P2TR}
+
 func (k *BtcKey) Type() KeyType {
 	return Btc
 }
 
-// SetTestNet allows producing keys for Bitcoin's TestNet3
-func (k *BtcKey) SetTestNet(b bool) {
-	k.testnet = b
+// SetNetwork allows producing keys for Bitcoin's test networks
+// (testnet3, testnet4, signet, regtest).
+func (k *BtcKey) SetNetwork(n Network) error {
+	if _, ok := k.supportedNetworks()[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	k.net = n
+	return nil
+}
+
+// AddressType returns the type of addresses produced by this key.
+func (k *BtcKey) AddressType() AddressType {
+	return k.addrType
+}
+
+// SetAddressType selects the type of addresses produced by this key,
+// along with the derivation paths and the extended key versions
+// (xpub, zpub...) that correspond to it.
+func (k *BtcKey) SetAddressType(t AddressType) error {
+	if t == P2WSH {
+		return fmt.Errorf("p2wsh addresses belong to multisig wallets and cannot be derived from a single key")
+	}
+	if _, ok := k.params().hdVersions[t]; !ok {
+		return fmt.Errorf("address type %s is not supported", t)
+	}
+	k.addrType = t
+	return nil
 }
 
 // FromString initializes a BtcKey with by importing a Bitcoin HD wallet
 // private or public key. They start with xpub... or xpriv...
+// SLIP-132 keys (ypub..., zpub...) are accepted too and set the address
+// type accordingly. When the key does not belong to the current network,
+// the network is switched to the one it belongs to.
 func (k *BtcKey) FromString(data string, priv bool) error {
-	key, err := hdkeychain.NewKeyFromString(string(data))
+	decoded := base58.Decode(data)
+	if len(decoded) < 4 {
+		return hdkeychain.ErrInvalidKeyLen
+	}
+
+	n, t, isPriv, ok := k.lookupVersion(decoded[:4])
+	if !ok {
+		return fmt.Errorf("unknown extended key version %x", decoded[:4])
+	}
+
+	// Keys are handled internally with xpub/xprv versions, which
+	// hdkeychain knows how to neuter.
+	version := xpubVersions.pub
+	if isPriv {
+		version = xpubVersions.priv
+	}
+	data, err := replaceVersion(data, version[:])
+	if err != nil {
+		return err
+	}
+
+	key, err := hdkeychain.NewKeyFromString(data)
 	if err != nil {
 		return err
 	}
@@ -42,17 +101,15 @@ func (k *BtcKey) FromString(data string, priv bool) error {
 	}
 
 	k.key = key
+	k.net = n
+	k.addrType = t
 	return nil
 }
 
 // FromSeed initializes a BtcKey from a Seed. The seed is just a slice of
 // of bytes (hopefully generated in a secure random fashion).
 func (k *BtcKey) FromSeed(s Seed) error {
-	params := &chaincfg.MainNetParams
-	if k.testnet {
-		params = &chaincfg.TestNet3Params
-	}
-	masterk, err := hdkeychain.NewMaster(s.Bytes(), params)
+	masterk, err := hdkeychain.NewMaster(s.Bytes(), &chaincfg.MainNetParams)
 	if err != nil {
 		return err
 	}
@@ -61,61 +118,126 @@ func (k *BtcKey) FromSeed(s Seed) error {
 }
 
 // GetMasterPub returns the Bitcoin-formatted master public
-// key (xpub...)
+// key (xpub..., or ypub.../zpub... for segwit addresses)
 func (k *BtcKey) GetMasterPub() (string, error) {
 	pubk, err := k.key.Neuter()
 	if err != nil {
 		return "", err
 	}
 
-	return pubk.String(), nil
+	return k.encodeExtendedKey(pubk)
 }
 
 // GetMasterPriv returns the Bitcoin-formatted private key
-// (xpriv...)
+// (xpriv..., or yprv.../zprv... for segwit addresses)
 func (k *BtcKey) GetMasterPriv() (string, error) {
-	return k.key.String(), nil
+	return k.encodeExtendedKey(k.key)
+}
+
+// SetElectrumSeedType makes the key use the derivation paths and the
+// address type of Electrum wallets of the given seed type.
+func (k *BtcKey) SetElectrumSeedType(t ElectrumSeedType) {
+	k.electrum = t
+	k.addrType = t.AddressType()
+}
+
+// AccountPath returns the account path for Bitcoin keys
+// (m/purpose'/coin'/account'), where purpose depends on the address type
+// (44 for P2PKH, 49 for P2SH-P2WPKH, 84 for P2WPKH, 86 for P2TR) and
+// coin is 0, or 1 for test networks. Keys of Electrum wallets use the
+// Electrum paths instead (see ElectrumSeedType.AccountPath).
+func (k *BtcKey) AccountPath(account uint32) Path {
+	if k.electrum != "" {
+		return k.electrum.AccountPath(account)
+	}
+	return AccountPath(k.addrType.Purpose(), k.params().coinType, account)
+}
+
+// CheckAccount returns an error when the account does not exist in the
+// key's Electrum wallet (see ElectrumSeedType.CheckAccount). Any account
+// is valid for other keys.
+func (k *BtcKey) CheckAccount(account uint32) error {
+	return k.electrum.CheckAccount(account)
 }
 
-// GetChildPrivKey derivates a private key from the master key
-// and returns an Bitcoin-importable WIF-encoded version of it.
-func (k *BtcKey) GetChildPrivKey(index int) (string, error) {
-	privk, err := k.GetChildPrivKeyBtc(index)
+// AddressPath returns the path for the given account, change
+// and address index (m/purpose'/coin'/account'/change/index).
+func (k *BtcKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+// GetAccountPub returns the Bitcoin-formatted extended public key
+// (xpub..., ypub..., zpub...) for the given account.
+func (k *BtcKey) GetAccountPub(account uint32) (string, error) {
+	if err := k.CheckAccount(account); err != nil {
+		return "", err
+	}
+	return k.GetExtendedPubKey(k.AccountPath(account))
+}
+
+// GetExtendedPubKey derivates the key at the given path and returns
+// its Bitcoin-formatted extended public key (xpub..., ypub..., zpub...).
+func (k *BtcKey) GetExtendedPubKey(p Path) (string, error) {
+	key, err := k.derive(p)
 	if err != nil {
 		return "", err
 	}
 
-	params := &chaincfg.MainNetParams
-	if k.testnet {
-		params = &chaincfg.TestNet3Params
+	pubk, err := key.Neuter()
+	if err != nil {
+		return "", err
 	}
-	wif, err := btcutil.NewWIF(privk, params, true)
+	return k.encodeExtendedKey(pubk)
+}
+
+// GetChildPrivKey derivates a private key from the master key following
+// the given path and returns an Bitcoin-importable WIF-encoded version of it.
+// For P2TR keys, the WIF key is wrapped in a tr() output descriptor, which
+// can be imported with Bitcoin Core's importdescriptors for key-path
+// spending.
+func (k *BtcKey) GetChildPrivKey(p Path) (string, error) {
+	privk, err := k.GetChildPrivKeyBtc(p)
 	if err != nil {
 		return "", err
 	}
 
-	return wif.String(), nil
+	wif := encodeWIF(privk, k.params().privateKeyID)
+	if k.addrType == P2TR {
+		return descriptorWithChecksum("tr(" + wif + ")"), nil
+	}
+	return wif, nil
 }
 
-// GetChildPubKey derivates a public key from the master key
-// and returns it formatted as a valid Bitcoin address.
-func (k *BtcKey) GetChildPubKey(index int) (string, error) {
-	ecpub, err := k.GetChildPubKeyBtc(index)
+// GetChildPubKey derivates a public key from the master key following
+// the given path and returns it formatted as a valid Bitcoin address
+// of the key's address type.
+func (k *BtcKey) GetChildPubKey(p Path) (string, error) {
+	if k.electrum.Multisig() {
+		return "", fmt.Errorf("Electrum %s wallets use multisig addresses, which cannot be derived from the seed alone", k.electrum)
+	}
+
+	ecpub, err := k.GetChildPubKeyBtc(p)
 	if err != nil {
 		return "", err
 	}
 
-	if k.testnet {
-		return encodeBitcoinPubkey(ecpub, []byte{chaincfg.TestNet3Params.PubKeyHashAddrID}), nil
+	params := k.params()
+	switch k.addrType {
+	case P2WPKH:
+		return encodeSegwitAddress(params.bech32HRP, 0, hash160(ecpub.SerializeCompressed()))
+	case P2SHP2WPKH:
+		return encodeNestedSegwitPubkey(ecpub, params.scriptHashAddrID), nil
+	case P2TR:
+		return encodeTaprootPubkey(ecpub, params.bech32HRP)
+	default:
+		return encodeBitcoinPubkey(ecpub, params.pubKeyHashAddrID), nil
 	}
-
-	return encodeBitcoinPubkey(ecpub, []byte{chaincfg.MainNetParams.PubKeyHashAddrID}), nil
 }
 
 // GetChildPrivKeyBtc derivates a Bitcoin private key from the master key
 // and returns it.
-func (k *BtcKey) GetChildPrivKeyBtc(index int) (*btcec.PrivateKey, error) {
-	childpriv, err := k.key.Child(uint32(index))
+func (k *BtcKey) GetChildPrivKeyBtc(p Path) (*btcec.PrivateKey, error) {
+	childpriv, err := k.derive(p)
 	if err != nil {
 		return nil, err
 	}
@@ -129,8 +251,8 @@ func (k *BtcKey) GetChildPrivKeyBtc(index int) (*btcec.PrivateKey, error) {
 
 // GetChildPubKeyBtc derivates a Bitcoin public key from the master key
 // and returns it.
-func (k *BtcKey) GetChildPubKeyBtc(index int) (*btcec.PublicKey, error) {
-	childpub, err := k.key.Child(uint32(index))
+func (k *BtcKey) GetChildPubKeyBtc(p Path) (*btcec.PublicKey, error) {
+	childpub, err := k.derive(p)
 	if err != nil {
 		return nil, err
 	}
@@ -143,16 +265,116 @@ func (k *BtcKey) GetChildPubKeyBtc(index int) (*btcec.PublicKey, error) {
 	return ecpub, nil
 }
 
-func encodeBitcoinPubkey(k *btcec.PublicKey, prefix []byte) string {
-	comp := k.SerializeCompressed()
-	shad := sha256.Sum256(comp)
+// derive walks the given path starting at the master key and returns
+// the resulting extended key. Private keys are padded to 32 bytes when
+// deriving hardened children, as required by BIP32 (older btcutil
+// versions did not, see btcsuite/btcutil#172).
+func (k *BtcKey) derive(p Path) (*hdkeychain.ExtendedKey, error) {
+	key := k.key
+	for _, i := range p {
+		child, err := key.Derive(i)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+func (k *BtcKey) supportedNetworks() map[Network]*btcParams {
+	if k.networks == nil {
+		return bitcoinNetworks
+	}
+	return k.networks
+}
+
+// params returns the encoding parameters for the key's network.
+func (k *BtcKey) params() *btcParams {
+	return k.supportedNetworks()[k.net]
+}
+
+// lookupVersion finds the network and address type that correspond to
+// the given extended key version, preferring the current network.
+func (k *BtcKey) lookupVersion(version []byte) (Network, AddressType, bool, bool) {
+	networks := []Network{k.net}
+	for n := Mainnet; n <= Simnet; n++ {
+		networks = append(networks, n)
+	}
+
+	for _, n := range networks {
+		params, ok := k.supportedNetworks()[n]
+		if !ok {
+			continue
+		}
+		for _, t := range btcAddressTypes {
+			v, ok := params.hdVersions[t]
+			if !ok {
+				continue
+			}
+			if bytes.Equal(version, v.pub[:]) {
+				return n, t, false, true
+			}
+			if bytes.Equal(version, v.priv[:]) {
+				return n, t, true, true
+			}
+		}
+	}
+	return 0, 0, false, false
+}
+
+// encodeExtendedKey serializes an extended key using the versions
+// for the key's address type and network.
+func (k *BtcKey) encodeExtendedKey(key *hdkeychain.ExtendedKey) (string, error) {
+	versions := k.params().hdVersions[k.addrType]
+	if key.IsPrivate() {
+		return replaceVersion(key.String(), versions.priv[:])
+	}
+	return replaceVersion(key.String(), versions.pub[:])
+}
+
+// replaceVersion re-encodes a base58-encoded extended key using
+// different version bytes.
+func replaceVersion(key string, version []byte) (string, error) {
+	decoded := base58.Decode(key)
+	if len(decoded) != 82 {
+		return "", hdkeychain.ErrInvalidKeyLen
+	}
+	payload := decoded[:78]
+	first := sha256.Sum256(payload)
+	chk := sha256.Sum256(first[:])
+	if !bytes.Equal(chk[:4], decoded[78:]) {
+		return "", hdkeychain.ErrBadChecksum
+	}
+	return base58Check(payload[4:], version), nil
+}
+
+func hash160(b []byte) []byte {
+	shad := sha256.Sum256(b)
 	h := ripemd160.New()
 	h.Write(shad[:])
-	return base58Check(h.Sum(nil), prefix)
+	return h.Sum(nil)
+}
+
+func encodeBitcoinPubkey(k *btcec.PublicKey, prefix []byte) string {
+	return base58Check(hash160(k.SerializeCompressed()), prefix)
+}
+
+// encodeNestedSegwitPubkey returns the P2SH address for a P2WPKH
+// redeem script (0 <hash160(pubkey)>).
+func encodeNestedSegwitPubkey(k *btcec.PublicKey, prefix []byte) string {
+	script := append([]byte{0x00, 0x14}, hash160(k.SerializeCompressed())...)
+	return base58Check(hash160(script), prefix)
+}
+
+// encodeWIF returns the Wallet Import Format encoding of a private key
+// for a compressed public key.
+func encodeWIF(k *btcec.PrivateKey, prefix byte) string {
+	privBytes := paddedBytes(k.D, btcec.PrivKeyBytesLen)
+	return base58Check(append(privBytes, 0x01), []byte{prefix})
 }
 
 func base58Check(val, prefix []byte) string {
-	val = append(prefix, val...)
+	val = append(append([]byte{}, prefix...), val...)
 	first := sha256.Sum256(val)
 	chk := sha256.Sum256(first[:])
 	return base58.Encode(append(val, chk[:4]...))
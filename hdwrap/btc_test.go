@@ -0,0 +1,62 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Hardened derivations from private keys shorter than 32 bytes, which
+// must be padded (btcsuite/btcutil#172). The first ones are from BIP32
+// test vector 4, where m/0' has a leading zero byte.
+var bip32LeadingZeroVectors = []struct {
+	seed string
+	path Path
+	priv string
+	xprv string
+}{
+	{
+		"3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+		Path{Hardened(0)},
+		"00d948e9261e41362a688b916f297121ba6bfb2274a3575ac0e456551dfd7f7e",
+		"xprv9vB7xEWwNp9kh1wQRfCCQMnZUEG21LpbR9NPCNN1dwhiZkjjeGRnaALmPXCX7SgjFTiCTT6bXes17boXtjq3xLpcDjzEuGLQBM5ohqkao9G",
+	},
+	{
+		"3ddd5602285899a946114506157c7997e5444528f3003f6134712147db19b678",
+		Path{Hardened(0), Hardened(1)},
+		"3a2086edd7d9df86c3487a5905a1712a9aa664bce8cc268141e07549eaa8661d",
+		"xprv9xJocDuwtYCMNAo3Zw76WENQeAS6WGXQ55RCy7tDJ8oALr4FWkuVoHJeHVAcAqiZLE7Je3vZJHxspZdFHfnBEjHqU5hG1Jaj32dVoS6XLT1",
+	},
+	{
+		"000000d900000000000000000000000000000000000000000000000000000000",
+		Path{Hardened(0), Hardened(0)},
+		"bd9854f2f4ec1ec294d29da41ded10f915525c18f79d6ed170a873e0eb5cd11c",
+		"xprv9w2VTNDYMYpdepM6QxYREQNLdfjo5JErTtr4FEEKmWgt5ayb3ykXBh8AHpRq6CGtik49iCH87wWDAU81HbCzDFriSnC4HoHCcMDeuuW8QWD",
+	},
+}
+
+func TestBtcDeriveLeadingZeros(t *testing.T) {
+	for _, v := range bip32LeadingZeroVectors {
+		seed, err := hex.DecodeString(v.seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := &BtcKey{}
+		if err := k.FromSeed(seed); err != nil {
+			t.Fatal(err)
+		}
+		key, err := k.derive(v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xprv := key.String(); xprv != v.xprv {
+			t.Errorf("%s: %s, expected %s", v.path, xprv, v.xprv)
+		}
+		priv, err := k.GetChildPrivKeyBtc(v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(priv.Serialize()); got != v.priv {
+			t.Errorf("%s: private key %s, expected %s", v.path, got, v.priv)
+		}
+	}
+}
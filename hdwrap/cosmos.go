@@ -0,0 +1,210 @@
+package hdwrap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/blowfish"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+// DefaultCosmosHRP is the bech32 prefix of Cosmos Hub addresses.
+const DefaultCosmosHRP = "cosmos"
+
+// Cosmos SDK armored private keys ("keys export"/"keys import") are
+// encrypted with xsalsa20-poly1305 (secretbox), using the SHA256 of a
+// bcrypt hash of the passphrase as key.
+const (
+	cosmosArmorBlockType = "TENDERMINT PRIVATE KEY"
+	cosmosBcryptCost     = 12
+	cosmosSaltSize       = 16
+	cosmosNonceSize      = 24
+)
+
+// cosmosAminoPrivKeyPrefix is the amino prefix of secp256k1 private
+// keys (tendermint/PrivKeySecp256k1), followed by the key length.
+var cosmosAminoPrivKeyPrefix = []byte{0xe1, 0xb0, 0xf7, 0x9b, 0x20}
+
+// bcryptEncoding is the base64 variant used by bcrypt hashes.
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+// CosmosKey works much like a BtcKey except for public address
+// generation: Cosmos SDK chains use the bech32 encoding of the hash160
+// of the compressed public key, with a prefix that identifies the
+// chain (cosmos, osmo, juno...).
+type CosmosKey struct {
+	key *BtcKey
+	hrp string
+}
+
+func (k *CosmosKey) Type() KeyType {
+	return Cosmos
+}
+
+// btc returns the wrapped BtcKey.
+func (k *CosmosKey) btc() *BtcKey {
+	if k.key == nil {
+		k.key = &BtcKey{}
+	}
+	return k.key
+}
+
+// SetNetwork affects only the format of the extended keys (tpub...
+// for test networks). Cosmos chains are identified by the address
+// prefix instead (see SetHRP).
+func (k *CosmosKey) SetNetwork(n Network) error {
+	if _, ok := bitcoinNetworks[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	return k.btc().SetNetwork(n)
+}
+
+// HRP returns the bech32 human-readable part of the addresses produced
+// by this key.
+func (k *CosmosKey) HRP() string {
+	if k.hrp == "" {
+		return DefaultCosmosHRP
+	}
+	return k.hrp
+}
+
+// SetHRP sets the bech32 human-readable part of the addresses, which
+// identifies the chain (cosmos, osmo, juno...).
+func (k *CosmosKey) SetHRP(hrp string) error {
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return fmt.Errorf("bech32 prefixes must have between 1 and 83 characters")
+	}
+	for _, c := range hrp {
+		if c < 33 || c > 126 || (c >= 'A' && c <= 'Z') {
+			return fmt.Errorf("invalid bech32 prefix: %s", hrp)
+		}
+	}
+	k.hrp = hrp
+	return nil
+}
+
+func (k *CosmosKey) FromString(data string, priv bool) error {
+	return k.btc().FromString(data, priv)
+}
+
+func (k *CosmosKey) FromSeed(s Seed) error {
+	return k.btc().FromSeed(s)
+}
+
+func (k *CosmosKey) GetMasterPub() (string, error) {
+	return k.key.GetMasterPub()
+}
+
+func (k *CosmosKey) GetMasterPriv() (string, error) {
+	return k.key.GetMasterPriv()
+}
+
+func (k *CosmosKey) AccountPath(account uint32) Path {
+	return AccountPath(BIP44Purpose, Cosmos.CoinType(), account)
+}
+
+func (k *CosmosKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+func (k *CosmosKey) GetAccountPub(account uint32) (string, error) {
+	return k.key.GetExtendedPubKey(k.AccountPath(account))
+}
+
+// GetChildPrivKey derivates a private key and returns it hex-encoded,
+// which can be imported with "keys import-hex". See ArmorChildPrivKey
+// for "keys import".
+func (k *CosmosKey) GetChildPrivKey(p Path) (string, error) {
+	privk, err := k.key.GetChildPrivKeyBtc(p)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", privk.Serialize()), nil
+}
+
+// ArmorChildPrivKey derivates a private key and returns it ASCII-armored
+// and encrypted with the given passphrase, as done by "keys export". It
+// can be imported with "keys import".
+func (k *CosmosKey) ArmorChildPrivKey(p Path, passphrase string) (string, error) {
+	privk, err := k.key.GetChildPrivKeyBtc(p)
+	if err != nil {
+		return "", err
+	}
+	random := make([]byte, cosmosSaltSize+cosmosNonceSize)
+	if _, err := io.ReadFull(rand.Reader, random); err != nil {
+		return "", err
+	}
+	plaintext := append(append([]byte{}, cosmosAminoPrivKeyPrefix...), privk.Serialize()...)
+	defer zero(plaintext)
+	return armorCosmosPrivKey(plaintext, passphrase, random[:cosmosSaltSize], random[cosmosSaltSize:])
+}
+
+// GetChildPubKey derivates a public key and returns its bech32 address.
+func (k *CosmosKey) GetChildPubKey(p Path) (string, error) {
+	ecpub, err := k.key.GetChildPubKeyBtc(p)
+	if err != nil {
+		return "", err
+	}
+	return bech32EncodeBytes(k.HRP(), hash160(ecpub.SerializeCompressed())), nil
+}
+
+// armorCosmosPrivKey encrypts the amino-encoded private key with the
+// passphrase, salt and nonce and returns the ASCII-armored result.
+func armorCosmosPrivKey(aminoKey []byte, passphrase string, salt, nonce []byte) (string, error) {
+	hash := cosmosBcrypt([]byte(passphrase), salt, cosmosBcryptCost)
+	key := sha256.Sum256(hash)
+	defer zero(key[:])
+
+	var n [cosmosNonceSize]byte
+	copy(n[:], nonce)
+	ciphertext := secretbox.Seal(append([]byte{}, nonce...), aminoKey, &n, &key)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, cosmosArmorBlockType, map[string]string{
+		"kdf":  "bcrypt",
+		"salt": fmt.Sprintf("%X", salt),
+		"type": "secp256k1",
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(ciphertext); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// cosmosBcrypt returns the bcrypt hash ($2a$...) of the passphrase with
+// the given salt, as the Cosmos SDK computes it to derive the encryption
+// key of armored private keys. Unlike golang.org/x/crypto/bcrypt, the
+// salt is not random.
+func cosmosBcrypt(passphrase, salt []byte, cost uint) []byte {
+	key := append(append([]byte{}, passphrase...), 0)
+	defer zero(key)
+	c, err := blowfish.NewSaltedCipher(key, salt)
+	if err != nil {
+		panic(err)
+	}
+	for i := uint64(0); i < 1<<cost; i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+
+	data := []byte("OrpheanBeholderScryDoubt")
+	for i := 0; i < len(data); i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(data[i:i+8], data[i:i+8])
+		}
+	}
+	return []byte(fmt.Sprintf("$2a$%02d$%s%s", cost,
+		bcryptEncoding.EncodeToString(salt),
+		bcryptEncoding.EncodeToString(data[:23])))
+}
@@ -0,0 +1,128 @@
+package hdwrap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func cosmosTestKey(t *testing.T) *CosmosKey {
+	seed, err := hex.DecodeString(bip86Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &CosmosKey{}
+	if err := k.FromSeed(seed); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// Addresses of the "abandon ... about" mnemonic at m/44'/118'/0'/0/0, as
+// given by cosmjs and Keplr.
+func TestCosmosAddress(t *testing.T) {
+	k := cosmosTestKey(t)
+	p := k.AddressPath(0, 0, 0)
+	if p.String() != "m/44'/118'/0'/0/0" {
+		t.Fatalf("unexpected address path %s", p)
+	}
+
+	for _, v := range []struct{ hrp, address string }{
+		{"cosmos", "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4"},
+		{"osmo", "osmo19rl4cm2hmr8afy4kldpxz3fka4jguq0a5m7df8"},
+	} {
+		if err := k.SetHRP(v.hrp); err != nil {
+			t.Fatal(err)
+		}
+		addr, err := k.GetChildPubKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != v.address {
+			t.Errorf("%s address %s, expected %s", v.hrp, addr, v.address)
+		}
+	}
+}
+
+func TestCosmosBcrypt(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	for _, passphrase := range []string{"", "secret", "a longer passphrase with spaces"} {
+		hash := cosmosBcrypt([]byte(passphrase), salt, 4)
+		if err := bcrypt.CompareHashAndPassword(hash, []byte(passphrase)); err != nil {
+			t.Errorf("%q: %s does not verify: %s", passphrase, hash, err)
+		}
+		if err := bcrypt.CompareHashAndPassword(hash, []byte(passphrase+"x")); err == nil {
+			t.Errorf("%q: %s verifies with the wrong passphrase", passphrase, hash)
+		}
+		if cost, err := bcrypt.Cost(hash); err != nil || cost != 4 {
+			t.Errorf("%q: unexpected cost %d (%v)", passphrase, cost, err)
+		}
+	}
+}
+
+func TestCosmosArmor(t *testing.T) {
+	k := cosmosTestKey(t)
+	p := k.AddressPath(0, 0, 0)
+	passphrase := "test passphrase"
+	armored, err := k.ArmorChildPrivKey(p, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Type != cosmosArmorBlockType {
+		t.Errorf("block type %s, expected %s", block.Type, cosmosArmorBlockType)
+	}
+	if block.Header["kdf"] != "bcrypt" || block.Header["type"] != "secp256k1" {
+		t.Errorf("unexpected headers: %v", block.Header)
+	}
+	salt, err := hex.DecodeString(block.Header["salt"])
+	if err != nil || len(salt) != cosmosSaltSize {
+		t.Fatalf("bad salt %q: %v", block.Header["salt"], err)
+	}
+	data, err := ioutil.ReadAll(block.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < cosmosNonceSize {
+		t.Fatalf("armored key too short: %d bytes", len(data))
+	}
+
+	open := func(passphrase string) ([]byte, bool) {
+		key := sha256.Sum256(cosmosBcrypt([]byte(passphrase), salt, cosmosBcryptCost))
+		var nonce [cosmosNonceSize]byte
+		copy(nonce[:], data)
+		return secretbox.Open(nil, data[cosmosNonceSize:], &nonce, &key)
+	}
+
+	plaintext, ok := open(passphrase)
+	if !ok {
+		t.Fatal("cannot decrypt the armored key")
+	}
+	hexPriv, err := k.GetChildPrivKey(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := hex.DecodeString(hexPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(append([]byte{}, cosmosAminoPrivKeyPrefix...), priv...)
+	if !bytes.Equal(plaintext, expected) {
+		t.Errorf("decrypted %x, expected %x", plaintext, expected)
+	}
+
+	if _, ok := open("wrong passphrase"); ok {
+		t.Error("armored key decrypted with the wrong passphrase")
+	}
+}
@@ -10,23 +10,42 @@ import (
 )
 
 type DcrKey struct {
-	key     *hdkeychain.ExtendedKey
-	testnet bool
+	key *hdkeychain.ExtendedKey
+	net Network
+}
+
+// decredNetworks lists the supported Decred networks.
+var decredNetworks = map[Network]*chaincfg.Params{
+	Mainnet:  &chaincfg.MainNetParams,
+	Testnet3: &chaincfg.TestNet3Params,
+	Simnet:   &chaincfg.SimNetParams,
+	Regtest:  &chaincfg.RegNetParams,
 }
 
 func (k *DcrKey) Type() KeyType {
 	return Dcr
 }
 
-func (k *DcrKey) SetTestNet(b bool) {
-	k.testnet = b
+// SetNetwork allows producing keys for Decred's testnet (testnet3),
+// simnet and regnet (regtest).
+func (k *DcrKey) SetNetwork(n Network) error {
+	params, ok := decredNetworks[n]
+	if !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	k.net = n
+	if k.key != nil {
+		k.key.SetNet(params)
+	}
+	return nil
 }
 
+// FromString imports a Decred extended key (dpub..., dprv..., tpub...).
+// When the key does not belong to the current network, the network is
+// switched to the one it belongs to.
 func (k *DcrKey) FromString(data string, priv bool) error {
-	fmt.Println(data)
 	key, err := hdkeychain.NewKeyFromString(string(data))
 	if err != nil {
-		fmt.Println("herE")
 		return err
 	}
 
@@ -34,16 +53,27 @@ func (k *DcrKey) FromString(data string, priv bool) error {
 		return fmt.Errorf("given key was not a private key")
 	}
 
+	if !key.IsForNet(k.params()) {
+		found := false
+		for n := Mainnet; n <= Simnet; n++ {
+			params, ok := decredNetworks[n]
+			if ok && key.IsForNet(params) {
+				k.net = n
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown extended key network")
+		}
+	}
+
 	k.key = key
 	return nil
 }
 
 func (k *DcrKey) FromSeed(s Seed) error {
-	params := &chaincfg.MainNetParams
-	if k.testnet {
-		params = &chaincfg.TestNet3Params
-	}
-	masterk, err := hdkeychain.NewMaster(s.Bytes(), params)
+	masterk, err := hdkeychain.NewMaster(s.Bytes(), k.params())
 	if err != nil {
 		return err
 	}
@@ -64,22 +94,39 @@ func (k *DcrKey) GetMasterPriv() (string, error) {
 	return k.key.String(), nil
 }
 
-func (k *DcrKey) GetChildPrivKey(index int) (string, error) {
-	childpriv, err := k.key.Child(uint32(index))
+func (k *DcrKey) AccountPath(account uint32) Path {
+	return AccountPath(BIP44Purpose, k.params().SLIP0044CoinType, account)
+}
+
+func (k *DcrKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+func (k *DcrKey) GetAccountPub(account uint32) (string, error) {
+	key, err := k.derive(k.AccountPath(account))
 	if err != nil {
 		return "", err
 	}
 
-	privk, err := childpriv.ECPrivKey()
+	pubk, err := key.Neuter()
 	if err != nil {
 		return "", err
 	}
+	return pubk.String(), nil
+}
 
-	params := &chaincfg.MainNetParams
-	if k.testnet {
-		params = &chaincfg.TestNet3Params
+func (k *DcrKey) GetChildPrivKey(p Path) (string, error) {
+	childpriv, err := k.derive(p)
+	if err != nil {
+		return "", err
 	}
-	wif, err := dcrutil.NewWIF(privk, params, dcrec.STEcdsaSecp256k1)
+
+	privk, err := childpriv.ECPrivKey()
+	if err != nil {
+		return "", err
+	}
+
+	wif, err := dcrutil.NewWIF(privk, k.params(), dcrec.STEcdsaSecp256k1)
 	if err != nil {
 		return "", err
 	}
@@ -87,20 +134,56 @@ func (k *DcrKey) GetChildPrivKey(index int) (string, error) {
 	return wif.String(), nil
 }
 
-func (k *DcrKey) GetChildPubKey(index int) (string, error) {
-	childpub, err := k.key.Child(uint32(index))
+func (k *DcrKey) GetChildPubKey(p Path) (string, error) {
+	childpub, err := k.derive(p)
 	if err != nil {
 		return "", err
 	}
 
-	params := &chaincfg.MainNetParams
-	if k.testnet {
-		params = &chaincfg.TestNet3Params
-	}
-
-	addr, err := childpub.Address(params)
+	addr, err := childpub.Address(k.params())
 	if err != nil {
 		return "", err
 	}
 	return addr.EncodeAddress(), nil
 }
+
+// params returns the chain parameters for the key's network.
+func (k *DcrKey) params() *chaincfg.Params {
+	return decredNetworks[k.net]
+}
+
+// derive walks the given path starting at the master key and returns
+// the resulting extended key.
+func (k *DcrKey) derive(p Path) (*hdkeychain.ExtendedKey, error) {
+	key := k.key
+	for _, i := range p {
+		if i >= HardenedKeyStart && key.IsPrivate() {
+			padded, err := padDcrKey(key)
+			if err != nil {
+				return nil, err
+			}
+			key = padded
+		}
+		child, err := key.Child(i)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// padDcrKey returns the private key with its key data padded to 32
+// bytes. hdkeychain does not pad private keys shorter than 32 bytes when
+// deriving hardened children, which BIP32 requires. The serialized key
+// is always padded, so it is parsed back when the key is short.
+func padDcrKey(key *hdkeychain.ExtendedKey) (*hdkeychain.ExtendedKey, error) {
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(priv.D.Bytes()) == 32 {
+		return key, nil
+	}
+	return hdkeychain.NewKeyFromString(key.String())
+}
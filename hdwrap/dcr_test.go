@@ -0,0 +1,30 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDcrDeriveLeadingZeros(t *testing.T) {
+	for _, v := range bip32LeadingZeroVectors {
+		seed, err := hex.DecodeString(v.seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := &DcrKey{}
+		if err := k.FromSeed(seed); err != nil {
+			t.Fatal(err)
+		}
+		key, err := k.derive(v.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		priv, err := key.ECPrivKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(priv.Serialize()); got != v.priv {
+			t.Errorf("%s: private key %s, expected %s", v.path, got, v.priv)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package hdwrap
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Supported sources of physical entropy
+const (
+	// Coin flips are entered as h/t (or 1/0).
+	Coin Die = 2
+	// D6 rolls are entered as digits from 1 to 6.
+	D6 Die = 6
+	// D20 rolls are entered as numbers from 1 to 20, separated by
+	// spaces or commas.
+	D20 Die = 20
+)
+
+// A Die is a source of physical entropy (dice or coins), identified
+// by its number of sides.
+type Die int
+
+// chiSquareCritical holds the critical values of the chi-square
+// distribution for a significance of 0.001, by number of sides.
+var chiSquareCritical = map[Die]float64{
+	Coin: 10.828,
+	D6:   20.515,
+	D20:  43.820,
+}
+
+// ParseDie returns the Die for the given name (d6, d20 or coin).
+func ParseDie(s string) (Die, error) {
+	switch strings.ToLower(s) {
+	case "coin", "coins":
+		return Coin, nil
+	case "d6":
+		return D6, nil
+	case "d20":
+		return D20, nil
+	default:
+		return 0, fmt.Errorf("unknown die: %s. Use d6 or d20", s)
+	}
+}
+
+// String returns the string representation of a Die
+func (d Die) String() string {
+	switch d {
+	case Coin:
+		return "coin"
+	case D6:
+		return "d6"
+	case D20:
+		return "d20"
+	default:
+		panic("bad die")
+	}
+}
+
+// EntropyBits returns the entropy provided by n fair rolls.
+func (d Die) EntropyBits(n int) float64 {
+	return float64(n) * math.Log2(float64(d))
+}
+
+// RollsNeeded returns the number of fair rolls needed to provide the
+// given bits of entropy.
+func (d Die) RollsNeeded(bits int) int {
+	return int(math.Ceil(float64(bits) / math.Log2(float64(d))))
+}
+
+// ParseRolls parses a list of results. Coin results are 0 (tails) or
+// 1 (heads); die results go from 1 to the number of sides.
+func (d Die) ParseRolls(s string) ([]int, error) {
+	var rolls []int
+	switch d {
+	case Coin, D6:
+		for _, c := range s {
+			if c == ' ' || c == ',' || c == '\t' {
+				continue
+			}
+			r, ok := d.parseRoll(string(c))
+			if !ok {
+				return nil, fmt.Errorf("invalid %s result: %q", d, c)
+			}
+			rolls = append(rolls, r)
+		}
+	default:
+		fields := strings.FieldsFunc(s, func(c rune) bool {
+			return c == ' ' || c == ',' || c == '\t'
+		})
+		for _, f := range fields {
+			r, ok := d.parseRoll(f)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s result: %q", d, f)
+			}
+			rolls = append(rolls, r)
+		}
+	}
+	return rolls, nil
+}
+
+func (d Die) parseRoll(s string) (int, bool) {
+	if d == Coin {
+		switch strings.ToLower(s) {
+		case "h", "1":
+			return 1, true
+		case "t", "0":
+			return 0, true
+		default:
+			return 0, false
+		}
+	}
+	r, err := strconv.Atoi(s)
+	if err != nil || r < 1 || r > int(d) {
+		return 0, false
+	}
+	return r, true
+}
+
+// CheckEntropy returns an error when the rolls do not provide the given
+// bits of entropy.
+func (d Die) CheckEntropy(rolls []int, bits int) error {
+	if len(rolls) < d.RollsNeeded(bits) {
+		return fmt.Errorf("not enough entropy: %d %s results provide %.1f bits, but %d bits (%d results) are needed",
+			len(rolls), d, d.EntropyBits(len(rolls)), bits, d.RollsNeeded(bits))
+	}
+	return nil
+}
+
+// CheckBias returns an error when the rolls show an obvious bias: a
+// frequency distribution or a run of repeated results which would be
+// very unlikely (p < 0.001) for a fair die or coin. It is a sanity check
+// for broken dice and input mistakes, not a proof of fairness.
+func (d Die) CheckBias(rolls []int) error {
+	n := len(rolls)
+	if n == 0 {
+		return nil
+	}
+
+	// Longest run of identical results. The expected number of runs of
+	// length k is about n / sides^(k-1).
+	longest, run := 1, 1
+	for i := 1; i < n; i++ {
+		if rolls[i] == rolls[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	if float64(n)*math.Pow(float64(d), -float64(longest-1)) < 0.001 {
+		return fmt.Errorf("the results contain %d identical %s results in a row", longest, d)
+	}
+
+	// Chi-square test, when there are enough results for it to be
+	// meaningful (5 expected per side).
+	expected := float64(n) / float64(d)
+	if expected < 5 {
+		return nil
+	}
+	counts := make(map[int]int)
+	for _, r := range rolls {
+		counts[r]++
+	}
+	first := 1
+	if d == Coin {
+		first = 0
+	}
+	var chi2 float64
+	for v := first; v < first+int(d); v++ {
+		diff := float64(counts[v]) - expected
+		chi2 += diff * diff / expected
+	}
+	if chi2 > chiSquareCritical[d] {
+		return fmt.Errorf("the %s results are not uniformly distributed (chi-square %.1f)", d, chi2)
+	}
+	return nil
+}
+
+// EncodeRolls returns the text representation of the rolls (space
+// separated numbers), from which seeds are derived.
+func EncodeRolls(rolls []int) []byte {
+	strs := make([]string, len(rolls))
+	for i, r := range rolls {
+		strs[i] = strconv.Itoa(r)
+	}
+	return []byte(strings.Join(strs, " "))
+}
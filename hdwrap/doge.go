@@ -0,0 +1,67 @@
+package hdwrap
+
+// DogeKey works much like a BtcKey except for public address generation,
+// which uses the Dogecoin network parameters (D... addresses and dgub
+// extended keys).
+type DogeKey struct {
+	key *BtcKey
+}
+
+func (k *DogeKey) Type() KeyType {
+	return Doge
+}
+
+// btc returns the wrapped BtcKey, initialized with the Dogecoin
+// network parameters.
+func (k *DogeKey) btc() *BtcKey {
+	if k.key == nil {
+		k.key = &BtcKey{networks: dogecoinNetworks}
+	}
+	return k.key
+}
+
+// SetNetwork allows producing keys for Dogecoin's testnet (testnet3)
+// and regtest.
+func (k *DogeKey) SetNetwork(n Network) error {
+	if _, ok := dogecoinNetworks[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	return k.btc().SetNetwork(n)
+}
+
+func (k *DogeKey) FromString(data string, priv bool) error {
+	return k.btc().FromString(data, priv)
+}
+
+func (k *DogeKey) FromSeed(s Seed) error {
+	return k.btc().FromSeed(s)
+}
+
+func (k *DogeKey) GetMasterPub() (string, error) {
+	return k.key.GetMasterPub()
+}
+
+func (k *DogeKey) GetMasterPriv() (string, error) {
+	return k.key.GetMasterPriv()
+}
+
+func (k *DogeKey) AccountPath(account uint32) Path {
+	return k.key.AccountPath(account)
+}
+
+func (k *DogeKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+func (k *DogeKey) GetAccountPub(account uint32) (string, error) {
+	return k.key.GetExtendedPubKey(k.AccountPath(account))
+}
+
+// GetChildPrivKey returns the WIF-encoded private key (Q...).
+func (k *DogeKey) GetChildPrivKey(p Path) (string, error) {
+	return k.key.GetChildPrivKey(p)
+}
+
+func (k *DogeKey) GetChildPubKey(p Path) (string, error) {
+	return k.key.GetChildPubKey(p)
+}
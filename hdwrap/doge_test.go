@@ -0,0 +1,101 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// Dogecoin keys of the "abandon ... about" mnemonic (account 0, first
+// receive address).
+var dogeVectors = []struct {
+	network    Network
+	path       string
+	address    string
+	wif        string
+	accountPub string
+}{
+	{Mainnet, "m/44'/3'/0'/0/0",
+		"DBus3bamQjgJULBJtYXpEzDWQRwF5iwxgC",
+		"QPkeC1ZfHx3c9g7WTj9cQ8gnvk2iSAfAcbq1aVAWjNTwDAKfZUzx",
+		"dgub8rUhDtD3YFGZTUphBfpBbzvFxSMKQXYLzg87Me2ta78r2SdVLmypBUkkxrrn9RTnchsyiJSkHZyLWxD13ibBiXtuFWktBoDaGaZjQUBLNLs"},
+	{Testnet3, "m/44'/1'/0'/0/0",
+		"nZVmfmUtKPmskB9Ds4P9GUJy9eYFqPKHqH",
+		"cnFdbfcpRPGq2kDure5SaiTBmxFDm38EdhvSWkEsHRWTw2h4kW7W",
+		"tgub5QziLPy2KFnZgdWALMfCBZcPpFQxtmu3sMDKvDdt1L7WAiAWXpAqf1S9FzNEEa7ipDM6kEy4o9mrVGshjxMKMoq383HELMHguMNGgRryJwt"},
+}
+
+func TestDogeKeys(t *testing.T) {
+	seed, err := hex.DecodeString(bip86Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range dogeVectors {
+		k := &DogeKey{}
+		if err := k.FromSeed(seed); err != nil {
+			t.Fatal(err)
+		}
+		if err := k.SetNetwork(v.network); err != nil {
+			t.Fatal(err)
+		}
+
+		p := k.AddressPath(0, 0, 0)
+		if p.String() != v.path {
+			t.Errorf("%s: address path %s, expected %s", v.network, p, v.path)
+		}
+		addr, err := k.GetChildPubKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != v.address {
+			t.Errorf("%s: address %s, expected %s", p, addr, v.address)
+		}
+		wif, err := k.GetChildPrivKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wif != v.wif {
+			t.Errorf("%s: private key %s, expected %s", p, wif, v.wif)
+		}
+		xpub, err := k.GetAccountPub(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xpub != v.accountPub {
+			t.Errorf("%s: account key %s, expected %s", v.network, xpub, v.accountPub)
+		}
+
+		imported := &DogeKey{}
+		if err := imported.FromString(v.accountPub, false); err != nil {
+			t.Fatal(err)
+		}
+		if pub, err := imported.GetMasterPub(); err != nil || pub != v.accountPub {
+			t.Errorf("%s: imported key %s (%v)", v.accountPub, pub, err)
+		}
+	}
+}
+
+func TestDogeVersions(t *testing.T) {
+	main := dogecoinNetworks[Mainnet]
+	checkBase58Prefix(t, main.pubKeyHashAddrID, 20, "D")
+	checkBase58Prefix(t, main.scriptHashAddrID, 20, "9A")
+	if main.privateKeyID != 0x9e {
+		t.Errorf("WIF version %x, expected 9e", main.privateKeyID)
+	}
+	checkBase58Prefix(t, []byte{main.privateKeyID}, 33, "Q")
+
+	test := dogecoinNetworks[Testnet3]
+	checkBase58Prefix(t, test.pubKeyHashAddrID, 20, "n")
+	checkBase58Prefix(t, test.scriptHashAddrID, 20, "2")
+	checkBase58Prefix(t, []byte{test.privateKeyID}, 33, "c")
+
+	wif := dogeVectors[0].wif
+	_, version, err := base58.CheckDecode(wif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0x9e {
+		t.Errorf("%s: version %x, expected 9e", wif, version)
+	}
+}
@@ -0,0 +1,194 @@
+package hdwrap
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Electrum seed types ("new" seeds, as introduced in Electrum 2.0)
+const (
+	// ElectrumStandard seeds are used by P2PKH wallets. Addresses are
+	// derived at m/change/index.
+	ElectrumStandard ElectrumSeedType = "standard"
+	// ElectrumSegwit seeds are used by P2WPKH wallets. Addresses are
+	// derived at m/0'/change/index.
+	ElectrumSegwit ElectrumSeedType = "segwit"
+	// Electrum2FA seeds are used by 2-of-3 multisig wallets with
+	// TrustedCoin as cosigner (P2SH).
+	Electrum2FA ElectrumSeedType = "2fa"
+	// Electrum2FASegwit seeds are used by 2-of-3 multisig wallets with
+	// TrustedCoin as cosigner (P2WSH).
+	Electrum2FASegwit ElectrumSeedType = "2fa-segwit"
+)
+
+// ElectrumSeedType identifies the kind of wallet an Electrum seed
+// belongs to, which is encoded in the seed itself.
+type ElectrumSeedType string
+
+// electrumSeedPrefixes are the prefixes of the hex-encoded
+// HMAC-SHA512("Seed version", mnemonic) for each seed type, in the order
+// they are checked by Electrum.
+var electrumSeedPrefixes = []struct {
+	prefix string
+	t      ElectrumSeedType
+}{
+	{"01", ElectrumStandard},
+	{"100", ElectrumSegwit},
+	{"101", Electrum2FA},
+	{"102", Electrum2FASegwit},
+}
+
+// electrumCJKRanges are the unicode ranges considered CJK by Electrum.
+// Whitespace between CJK characters is removed when normalizing seeds.
+var electrumCJKRanges = [][2]rune{
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x20000, 0x2A6DF}, // CJK Unified Ideographs Extension B
+	{0x2A700, 0x2B73F}, // CJK Unified Ideographs Extension C
+	{0x2B740, 0x2B81F}, // CJK Unified Ideographs Extension D
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0x2F800, 0x2FA1D}, // CJK Compatibility Ideographs Supplement
+	{0x3190, 0x319F},   // Kanbun
+	{0x2E80, 0x2EFF},   // CJK Radicals Supplement
+	{0x2F00, 0x2FDF},   // CJK Radicals
+	{0x31C0, 0x31EF},   // CJK Strokes
+	{0x2FF0, 0x2FFF},   // Ideographic Description Characters
+	{0xE0100, 0xE01EF}, // Variation Selectors Supplement
+	{0x3100, 0x312F},   // Bopomofo
+	{0x31A0, 0x31BF},   // Bopomofo Extended
+	{0xFF00, 0xFFEF},   // Halfwidth and Fullwidth Forms
+	{0x3040, 0x309F},   // Hiragana
+	{0x30A0, 0x30FF},   // Katakana
+	{0x31F0, 0x31FF},   // Katakana Phonetic Extensions
+	{0x1B000, 0x1B0FF}, // Kana Supplement
+	{0xAC00, 0xD7AF},   // Hangul Syllables
+	{0x1100, 0x11FF},   // Hangul Jamo
+	{0xA960, 0xA97F},   // Hangul Jamo Extended A
+	{0xD7B0, 0xD7FF},   // Hangul Jamo Extended B
+	{0x3130, 0x318F},   // Hangul Compatibility Jamo
+	{0xA4D0, 0xA4FF},   // Lisu
+	{0x16F00, 0x16F9F}, // Miao
+	{0xA000, 0xA48F},   // Yi Syllables
+	{0xA490, 0xA4CF},   // Yi Radicals
+}
+
+// ElectrumSeedTypeOf returns the type of an Electrum "new" seed. It
+// returns an error when the mnemonic is not an Electrum seed (i.e. it
+// contains a typo). Old (pre-2.0) Electrum seeds are not supported.
+func ElectrumSeedTypeOf(mnemonic string) (ElectrumSeedType, error) {
+	mac := hmac.New(sha512.New, []byte("Seed version"))
+	mac.Write([]byte(normalizeElectrum(mnemonic)))
+	version := hex.EncodeToString(mac.Sum(nil))
+	for _, p := range electrumSeedPrefixes {
+		if strings.HasPrefix(version, p.prefix) {
+			return p.t, nil
+		}
+	}
+	return "", fmt.Errorf("not a valid Electrum seed")
+}
+
+// NewElectrumSeedFile returns a SeedFile for an Electrum seed, deriving
+// the Seed with the given passphrase.
+func NewElectrumSeedFile(mnemonic, passphrase string) (*SeedFile, error) {
+	if _, err := ElectrumSeedTypeOf(mnemonic); err != nil {
+		return nil, err
+	}
+	return &SeedFile{
+		Scheme: SchemeElectrum,
+		Seed:   electrumSeed(mnemonic, passphrase),
+		Phrase: strings.Join(strings.Fields(mnemonic), " "),
+	}, nil
+}
+
+// AccountPath returns the root of the keys of Electrum wallets for the
+// seed type. Standard and segwit wallets have a single account, at m
+// and m/0' respectively. For 2FA wallets, accounts 0 and 1 are the
+// wallet's own cosigner keys (m/0' and m/1'). The account is ignored
+// for standard and segwit wallets: use CheckAccount to reject other
+// accounts.
+func (t ElectrumSeedType) AccountPath(account uint32) Path {
+	switch t {
+	case ElectrumStandard:
+		return Path{}
+	case ElectrumSegwit:
+		return Path{Hardened(0)}
+	default:
+		return Path{Hardened(account)}
+	}
+}
+
+// CheckAccount returns an error when the seed type has no such account.
+// Standard and segwit wallets only have account 0.
+func (t ElectrumSeedType) CheckAccount(account uint32) error {
+	if account != 0 && (t == ElectrumStandard || t == ElectrumSegwit) {
+		return fmt.Errorf("Electrum %s wallets have a single account (0), not %d", t, account)
+	}
+	return nil
+}
+
+// AddressType returns the type of the addresses of single-signature
+// wallets for the seed type, and the type of the cosigner extended keys
+// for 2FA wallets: P2SH multisig wallets use xpub keys and P2WSH ones
+// use Zpub keys.
+func (t ElectrumSeedType) AddressType() AddressType {
+	switch t {
+	case ElectrumSegwit:
+		return P2WPKH
+	case Electrum2FASegwit:
+		return P2WSH
+	default:
+		return P2PKH
+	}
+}
+
+// Multisig returns true for seed types of multisig wallets, whose
+// addresses cannot be derived from the seed alone.
+func (t ElectrumSeedType) Multisig() bool {
+	return t == Electrum2FA || t == Electrum2FASegwit
+}
+
+// electrumSeed stretches an Electrum mnemonic and passphrase into a seed
+// with PBKDF2-HMAC-SHA512 (2048 iterations, "electrum" salt prefix).
+func electrumSeed(mnemonic, passphrase string) Seed {
+	salt := "electrum" + normalizeElectrum(passphrase)
+	return pbkdf2.Key([]byte(normalizeElectrum(mnemonic)), []byte(salt), 2048, 64, sha512.New)
+}
+
+// normalizeElectrum normalizes text as Electrum does for seeds and
+// passphrases: NFKD, lower case, no accents, single spaces and no
+// spaces between CJK characters.
+func normalizeElectrum(s string) string {
+	s = strings.ToLower(normalizeNFKD(s))
+	s = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == ' ' && isElectrumCJK(runes[i-1]) && isElectrumCJK(runes[i+1]) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isElectrumCJK(r rune) bool {
+	for _, rng := range electrumCJKRanges {
+		if r >= rng[0] && r <= rng[1] {
+			return true
+		}
+	}
+	return false
+}
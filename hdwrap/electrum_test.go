@@ -0,0 +1,215 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Seeds of Electrum's test suite (electrum/tests/test_wallet_vertical.py
+// and test_mnemonic.py) for each seed type.
+const (
+	electrumStandardSeed  = "cycle rocket west magnet parrot shuffle foot correct salt library feed song"
+	electrumSegwitSeed    = "bitter grass shiver impose acquire brush forget axis eager alone wine silver"
+	electrum2FASeed       = "kiss live scene rude gate step hip quarter bunker oxygen motor glove"
+	electrum2FASegwitSeed = "universe topic remind silver february ranch shine worth innocent cattle enhance wise"
+)
+
+func electrumKey(t *testing.T, mnemonic string) *BtcKey {
+	t.Helper()
+	seedType, err := ElectrumSeedTypeOf(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err := NewElectrumSeedFile(mnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &BtcKey{}
+	if err := k.FromSeed(sf.Seed); err != nil {
+		t.Fatal(err)
+	}
+	k.SetElectrumSeedType(seedType)
+	return k
+}
+
+func TestElectrumSeedType(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		seedType ElectrumSeedType
+	}{
+		{electrumStandardSeed, ElectrumStandard},
+		{electrumSegwitSeed, ElectrumSegwit},
+		{electrum2FASeed, Electrum2FA},
+		{electrum2FASegwitSeed, Electrum2FASegwit},
+		{"wild father tree among universe such mobile favorite target dynamic credit identify", ElectrumSegwit},
+	}
+	for _, tc := range tests {
+		seedType, err := ElectrumSeedTypeOf(tc.mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seedType != tc.seedType {
+			t.Errorf("%s: type %s, expected %s", tc.mnemonic, seedType, tc.seedType)
+		}
+	}
+
+	if _, err := ElectrumSeedTypeOf(bip86Mnemonic); err == nil {
+		t.Error("expected an error for a BIP39 mnemonic")
+	}
+}
+
+// Seeds stretched with PBKDF2 and the "electrum" salt prefix, from
+// Electrum's test_mnemonic.py.
+func TestElectrumSeed(t *testing.T) {
+	tests := []struct {
+		mnemonic   string
+		passphrase string
+		seed       string
+	}{
+		{
+			"wild father tree among universe such mobile favorite target dynamic credit identify", "",
+			"aac2a6302e48577ab4b46f23dbae0774e2e62c796f797d0a1b5faeb528301e3064342dafb79069e7c4c6b8c38ae11d7a973bec0d4f70626f8cc5184a8d0b0756",
+		},
+		{
+			"wild father tree among universe such mobile favorite target dynamic credit identify", "Did you ever hear the tragedy of Darth Plagueis the Wise?",
+			"4aa29f2aeb0127efb55138ab9e7be83b36750358751906f86c662b21a1ea1370f949e6d1a12fa56d3d93cadda93038c76ac8118597364e46f5156fde6183c82f",
+		},
+		{
+			"foobar", "none",
+			"741b72fd15effece6bfe5a26a52184f66811bd2be363190e07a42cca442b1a5bb22b3ad0eb338197287e6d314866c7fba863ac65d3f156087a5052ebc7157fce",
+		},
+	}
+	for _, tc := range tests {
+		if got := hex.EncodeToString(electrumSeed(tc.mnemonic, tc.passphrase)); got != tc.seed {
+			t.Errorf("%q: seed %s, expected %s", tc.passphrase, got, tc.seed)
+		}
+	}
+}
+
+func TestElectrumWallets(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		xpub     string
+		receive  string
+		change   string
+	}{
+		{
+			electrumStandardSeed,
+			"xpub661MyMwAqRbcFWohJWt7PHsFEJfZAvw9ZxwQoDa4SoMgsDDM1T7WK3u9E4edkC4ugRnZ8E4xDZRpk8Rnts3Nbt97dPwT52CwBdDWroaZf8U",
+			"1NNkttn1YvVGdqBW4PR6zvc3Zx3H5owKRf",
+			"1KSezYMhAJMWqFbVFB2JshYg69UpmEXR4D",
+		},
+		{
+			electrumSegwitSeed,
+			"zpub6nsHdRuY92FsMKdbn9BfjBCG6X8pyhCibNP6uDvpnw2cyrVhecvHRMa3Ne8kdJZxjxgwnpbHLkcR4bfnhHy6auHPJyDTQ3kianeuVLdkCYQ",
+			"bc1q3g5tmkmlvxryhh843v4dz026avatc0zzr6h3af",
+			"bc1qdy94n2q5qcp0kg7v9yzwe6wvfkhnvyzje7nx2p",
+		},
+	}
+	for _, tc := range tests {
+		k := electrumKey(t, tc.mnemonic)
+		xpub, err := k.GetAccountPub(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xpub != tc.xpub {
+			t.Errorf("%s: %s, expected %s", k.electrum, xpub, tc.xpub)
+		}
+		for i, expected := range []string{tc.receive, tc.change} {
+			addr, err := k.GetChildPubKey(k.AddressPath(0, uint32(i), 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if addr != expected {
+				t.Errorf("%s: %s, expected %s", k.electrum, addr, expected)
+			}
+		}
+	}
+
+	k := electrumKey(t, electrumStandardSeed)
+	xprv, err := k.GetMasterPriv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "xprv9s21ZrQH143K32jECVM729vWgGq4mUDJCk1ozqAStTphzQtCTuoFmFafNoG1g55iCnBTXUzz3zWnDb5CVLGiFvmaZjuazHDL8a81cPQ8KL6"; xprv != expected {
+		t.Errorf("standard: %s, expected %s", xprv, expected)
+	}
+}
+
+// 2FA wallets export their cosigner keys (m/0' and m/1') as xpub keys
+// (P2SH multisig) or Zpub/Vpub keys (P2WSH multisig).
+func TestElectrum2FAKeys(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		network  Network
+		prefix   string
+	}{
+		{electrum2FASeed, Mainnet, "xpub"},
+		{electrum2FASeed, Testnet3, "tpub"},
+		{electrum2FASegwitSeed, Mainnet, "Zpub"},
+		{electrum2FASegwitSeed, Testnet3, "Vpub"},
+	}
+	for _, tc := range tests {
+		k := electrumKey(t, tc.mnemonic)
+		if err := k.SetNetwork(tc.network); err != nil {
+			t.Fatal(err)
+		}
+		plain := &BtcKey{key: k.key, net: tc.network}
+		for account := uint32(0); account < 2; account++ {
+			pub, err := k.GetAccountPub(account)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(pub, tc.prefix) {
+				t.Errorf("%s %s: %s, expected a %s key", k.electrum, tc.network, pub, tc.prefix)
+			}
+
+			// The key is the BIP32 key at m/account'.
+			xpub, err := plain.GetExtendedPubKey(Path{Hardened(account)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			versions := plain.params().hdVersions[P2PKH]
+			if got, _ := replaceVersion(pub, versions.pub[:]); got != xpub {
+				t.Errorf("%s %s: %s is not the key at m/%d'", k.electrum, tc.network, pub, account)
+			}
+		}
+		if _, err := k.GetChildPubKey(k.AddressPath(0, 0, 0)); err == nil {
+			t.Errorf("%s: expected an error deriving a multisig address", k.electrum)
+		}
+	}
+
+	if err := (&BtcKey{}).SetAddressType(P2WSH); err == nil {
+		t.Error("expected an error selecting p2wsh addresses")
+	}
+}
+
+func TestElectrumAccounts(t *testing.T) {
+	tests := []struct {
+		mnemonic string
+		accounts []uint32
+		valid    bool
+	}{
+		{electrumStandardSeed, []uint32{1, 5}, false},
+		{electrumSegwitSeed, []uint32{1, 5}, false},
+		{electrum2FASeed, []uint32{0, 1}, true},
+		{electrum2FASegwitSeed, []uint32{0, 1}, true},
+	}
+
+	for _, tc := range tests {
+		k := electrumKey(t, tc.mnemonic)
+		if _, err := k.GetAccountPub(0); err != nil {
+			t.Errorf("%s: account 0: %s", k.electrum, err)
+		}
+		for _, account := range tc.accounts {
+			_, err := k.GetAccountPub(account)
+			if tc.valid && err != nil {
+				t.Errorf("%s: account %d: %s", k.electrum, account, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("%s: account %d: expected an error", k.electrum, account)
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package hdwrap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF identifies the function used to derive the encryption key of
+// encrypted seed files from a passphrase.
+type KDF string
+
+// Supported key derivation functions
+const (
+	KDFArgon2id KDF = "argon2id"
+	KDFScrypt   KDF = "scrypt"
+)
+
+// Default key derivation parameters. They are stored along with the
+// encrypted seed, so they can be raised without breaking existing files.
+const (
+	argon2idTime    = 3
+	argon2idMemory  = 64 * 1024
+	argon2idThreads = 4
+	scryptN         = 1 << 15
+	scryptR         = 8
+	scryptP         = 1
+)
+
+const seedCipher = "xchacha20-poly1305"
+
+// PassphraseFunc is called to obtain the passphrase of an encrypted
+// seed file, usually by prompting the user.
+type PassphraseFunc func() ([]byte, error)
+
+// ErrEncryptedSeedFile is returned when reading an encrypted seed file
+// without a PassphraseFunc.
+var ErrEncryptedSeedFile = errors.New("the seed file is encrypted")
+
+// ErrWrongPassphrase is returned when an encrypted seed file cannot be
+// decrypted with the given passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted seed file")
+
+// ParseKDF returns the KDF for the given name (argon2id or scrypt).
+func ParseKDF(s string) (KDF, error) {
+	switch kdf := KDF(s); kdf {
+	case KDFArgon2id, KDFScrypt:
+		return kdf, nil
+	default:
+		return "", fmt.Errorf("unknown key derivation function: %s", s)
+	}
+}
+
+type encryptedSeedFileJSON struct {
+	Encryption *seedEncryptionJSON `json:"encryption"`
+	Ciphertext string              `json:"ciphertext"`
+}
+
+type seedEncryptionJSON struct {
+	KDF  KDF    `json:"kdf"`
+	Salt string `json:"salt"`
+	// argon2id parameters
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	// scrypt parameters
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+
+	Cipher string `json:"cipher"`
+	Nonce  string `json:"nonce"`
+}
+
+// encryptSeedData encrypts the contents of a seed file with a key
+// derived from the passphrase and returns the encrypted seed file.
+func encryptSeedData(plaintext, passphrase []byte, kdf KDF) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase cannot be empty")
+	}
+
+	salt := make([]byte, 16)
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	enc := &seedEncryptionJSON{
+		KDF:    kdf,
+		Salt:   hex.EncodeToString(salt),
+		Cipher: seedCipher,
+		Nonce:  hex.EncodeToString(nonce),
+	}
+	switch kdf {
+	case KDFArgon2id:
+		enc.Time = argon2idTime
+		enc.Memory = argon2idMemory
+		enc.Threads = argon2idThreads
+	case KDFScrypt:
+		enc.N = scryptN
+		enc.R = scryptR
+		enc.P = scryptP
+	default:
+		return nil, fmt.Errorf("unknown key derivation function: %s", kdf)
+	}
+
+	key, err := enc.deriveKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	esf := encryptedSeedFileJSON{
+		Encryption: enc,
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}
+	data, err := json.MarshalIndent(esf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// isEncryptedSeedData returns true when the given seed file contents
+// correspond to an encrypted seed file.
+func isEncryptedSeedData(data []byte) bool {
+	var esf encryptedSeedFileJSON
+	if err := json.Unmarshal(data, &esf); err != nil {
+		return false
+	}
+	return esf.Encryption != nil
+}
+
+// decryptSeedData decrypts an encrypted seed file, obtaining the
+// passphrase from the given function, and returns the contents of
+// the original seed file.
+func decryptSeedData(data []byte, passphrase PassphraseFunc) ([]byte, error) {
+	var esf encryptedSeedFileJSON
+	if err := json.Unmarshal(data, &esf); err != nil {
+		return nil, fmt.Errorf("error parsing encrypted seed file: %s", err)
+	}
+	enc := esf.Encryption
+	if enc.Cipher != seedCipher {
+		return nil, fmt.Errorf("unknown seed file cipher: %s", enc.Cipher)
+	}
+	nonce, err := hex.DecodeString(enc.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("bad seed file nonce length")
+	}
+	ciphertext, err := hex.DecodeString(esf.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if passphrase == nil {
+		return nil, ErrEncryptedSeedFile
+	}
+	pass, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	defer zero(pass)
+
+	key, err := enc.deriveKey(pass)
+	if err != nil {
+		return nil, err
+	}
+	defer zero(key)
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func (enc *seedEncryptionJSON) deriveKey(passphrase []byte) ([]byte, error) {
+	salt, err := hex.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch enc.KDF {
+	case KDFArgon2id:
+		if enc.Time == 0 || enc.Memory == 0 || enc.Threads == 0 {
+			return nil, fmt.Errorf("bad argon2id parameters")
+		}
+		return argon2.IDKey(passphrase, salt, enc.Time, enc.Memory, enc.Threads, chacha20poly1305.KeySize), nil
+	case KDFScrypt:
+		return scrypt.Key(passphrase, salt, enc.N, enc.R, enc.P, chacha20poly1305.KeySize)
+	default:
+		return nil, fmt.Errorf("unknown key derivation function: %s", enc.KDF)
+	}
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
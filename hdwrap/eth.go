@@ -9,27 +9,36 @@ import (
 
 // EthKey works much like a BtcKey except for public address generation
 type EthKey struct {
-	key     *BtcKey
-	testnet bool
+	key *BtcKey
 }
 
 func (k *EthKey) Type() KeyType {
 	return Eth
 }
 
-func (k *EthKey) SetTestNet(b bool) {
-	k.testnet = b
-	k.key.SetTestNet(b)
+// btc returns the wrapped BtcKey.
+func (k *EthKey) btc() *BtcKey {
+	if k.key == nil {
+		k.key = &BtcKey{}
+	}
+	return k.key
+}
+
+// SetNetwork affects only the format of the extended keys (tpub...
+// for test networks). Ethereum addresses are the same in all networks.
+func (k *EthKey) SetNetwork(n Network) error {
+	if _, ok := bitcoinNetworks[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	return k.btc().SetNetwork(n)
 }
 
 func (k *EthKey) FromString(data string, priv bool) error {
-	k.key = &BtcKey{}
-	return k.key.FromString(data, priv)
+	return k.btc().FromString(data, priv)
 }
 
 func (k *EthKey) FromSeed(s Seed) error {
-	k.key = &BtcKey{}
-	return k.key.FromSeed(s)
+	return k.btc().FromSeed(s)
 }
 
 func (k *EthKey) GetMasterPub() (string, error) {
@@ -40,24 +49,32 @@ func (k *EthKey) GetMasterPriv() (string, error) {
 	return k.key.GetMasterPriv()
 }
 
-func (k *EthKey) GetChildPrivKey(index int) (string, error) {
-	privk, err := k.key.GetChildPrivKeyBtc(index)
+func (k *EthKey) AccountPath(account uint32) Path {
+	return AccountPath(BIP44Purpose, Eth.CoinType(), account)
+}
+
+func (k *EthKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+func (k *EthKey) GetAccountPub(account uint32) (string, error) {
+	return k.key.GetExtendedPubKey(k.AccountPath(account))
+}
+
+func (k *EthKey) GetChildPrivKey(p Path) (string, error) {
+	privk, err := k.key.GetChildPrivKeyBtc(p)
 	if err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("%x", privk.Serialize()), nil
 }
 
-func (k *EthKey) GetChildPubKey(index int) (string, error) {
-	ecpub, err := k.key.GetChildPubKeyBtc(index)
+func (k *EthKey) GetChildPubKey(p Path) (string, error) {
+	ecpub, err := k.key.GetChildPubKeyBtc(p)
 	if err != nil {
 		return "", err
 	}
 
-	if k.testnet {
-		return encodeEthereumPubkey(ecpub), nil
-	}
-
 	return encodeEthereumPubkey(ecpub), nil
 }
 
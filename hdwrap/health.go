@@ -0,0 +1,220 @@
+package hdwrap
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Health tests for randomness sources, modeled after NIST SP 800-90B.
+// The bytes are treated as 8-bit samples which should have full
+// entropy. The tests detect broken or obviously weak sources (i.e. a
+// file of zeros or text); they cannot prove that a source is random.
+
+const (
+	// healthAlpha is the false positive probability of the
+	// repetition count and adaptive proportion tests (2^-30).
+	healthAlpha = 1.0 / (1 << 30)
+	// healthWindow is the adaptive proportion test window size for
+	// non-binary samples.
+	healthWindow = 512
+	// healthClaimedEntropy is the entropy per sample claimed for
+	// randomness sources, in bits.
+	healthClaimedEntropy = 8
+	// healthMinEntropyBits is the minimum total min-entropy required.
+	healthMinEntropyBits = 256
+	// chi-square significance: z-score for p = 0.0001
+	healthChiSquareZ = 3.719
+)
+
+// HealthTest is the result of a single health test.
+type HealthTest struct {
+	Name   string
+	Passed bool
+	Result string
+}
+
+// HealthReport holds the results of the health tests run on the
+// bytes read from a randomness source.
+type HealthReport struct {
+	Samples int
+	// MinEntropy is the estimated min-entropy per byte (most common
+	// value estimate), in bits.
+	MinEntropy float64
+	Tests      []HealthTest
+}
+
+// Passed returns true when all tests passed.
+func (r *HealthReport) Passed() bool {
+	for _, t := range r.Tests {
+		if !t.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a human-readable version of the report.
+func (r *HealthReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Health tests on %d bytes of randomness:\n", r.Samples)
+	for _, t := range r.Tests {
+		status := "PASS"
+		if !t.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "  %-4s %-28s %s\n", status, t.Name, t.Result)
+	}
+	return b.String()
+}
+
+// CheckRandomness runs the repetition count, adaptive proportion,
+// chi-square and min-entropy tests on the given bytes.
+func CheckRandomness(data []byte) *HealthReport {
+	r := &HealthReport{Samples: len(data)}
+	r.Tests = append(r.Tests,
+		repetitionCountTest(data),
+		adaptiveProportionTest(data),
+		chiSquareTest(data),
+	)
+	r.MinEntropy = mostCommonValueEntropy(data)
+	total := r.MinEntropy * float64(len(data))
+	r.Tests = append(r.Tests, HealthTest{
+		Name:   "min-entropy (MCV estimate)",
+		Passed: total >= healthMinEntropyBits,
+		Result: fmt.Sprintf("%.2f bits/byte, %.0f bits in total (minimum %d)", r.MinEntropy, total, healthMinEntropyBits),
+	})
+	return r
+}
+
+// repetitionCountTest fails when a value repeats more times in a row
+// than would be expected, with probability alpha, from the claimed
+// entropy (SP 800-90B 4.4.1).
+func repetitionCountTest(data []byte) HealthTest {
+	cutoff := 1 + int(math.Ceil(-math.Log2(healthAlpha)/healthClaimedEntropy))
+	longest, run := 0, 0
+	for i := range data {
+		if i > 0 && data[i] == data[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return HealthTest{
+		Name:   "repetition count",
+		Passed: longest < cutoff,
+		Result: fmt.Sprintf("longest run %d (cutoff %d)", longest, cutoff),
+	}
+}
+
+// adaptiveProportionTest fails when the first value of a window
+// appears too many times in it (SP 800-90B 4.4.2).
+func adaptiveProportionTest(data []byte) HealthTest {
+	if len(data) < healthWindow {
+		return HealthTest{
+			Name:   "adaptive proportion",
+			Passed: true,
+			Result: fmt.Sprintf("skipped (less than %d bytes)", healthWindow),
+		}
+	}
+
+	cutoff := binomialCutoff(healthWindow, math.Pow(2, -healthClaimedEntropy), healthAlpha)
+	worst := 0
+	for start := 0; start+healthWindow <= len(data); start += healthWindow {
+		window := data[start : start+healthWindow]
+		count := 0
+		for _, v := range window {
+			if v == window[0] {
+				count++
+			}
+		}
+		if count > worst {
+			worst = count
+		}
+	}
+	return HealthTest{
+		Name:   "adaptive proportion",
+		Passed: worst < cutoff,
+		Result: fmt.Sprintf("max count %d in %d-byte windows (cutoff %d)", worst, healthWindow, cutoff),
+	}
+}
+
+// chiSquareTest checks that byte values are uniformly distributed
+// (SP 800-90B 5.2.1).
+func chiSquareTest(data []byte) HealthTest {
+	const bins = 256
+	expected := float64(len(data)) / bins
+	// The test needs at least 5 expected samples per bin.
+	if expected < 5 {
+		return HealthTest{
+			Name:   "chi-square",
+			Passed: true,
+			Result: fmt.Sprintf("skipped (less than %d bytes)", 5*bins),
+		}
+	}
+
+	var counts [bins]int
+	for _, v := range data {
+		counts[v]++
+	}
+	var chi2 float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+
+	// Wilson-Hilferty approximation of the critical value.
+	df := float64(bins - 1)
+	k := 2 / (9 * df)
+	critical := df * math.Pow(1-k+healthChiSquareZ*math.Sqrt(k), 3)
+	return HealthTest{
+		Name:   "chi-square",
+		Passed: chi2 <= critical,
+		Result: fmt.Sprintf("%.1f (critical value %.1f)", chi2, critical),
+	}
+}
+
+// mostCommonValueEntropy returns the most common value min-entropy
+// estimate per sample (SP 800-90B 6.3.1).
+func mostCommonValueEntropy(data []byte) float64 {
+	if len(data) < 2 {
+		return 0
+	}
+	var counts [256]int
+	max := 0
+	for _, v := range data {
+		counts[v]++
+		if counts[v] > max {
+			max = counts[v]
+		}
+	}
+	n := float64(len(data))
+	p := float64(max) / n
+	pu := p + 2.576*math.Sqrt(p*(1-p)/(n-1))
+	if pu >= 1 {
+		return 0
+	}
+	return -math.Log2(pu)
+}
+
+// binomialCutoff returns the smallest c such that the probability of
+// c or more successes in n trials with probability p is below alpha.
+func binomialCutoff(n int, p, alpha float64) int {
+	// pmf(k) computed iteratively in log space
+	logPmf := make([]float64, n+1)
+	logPmf[0] = float64(n) * math.Log1p(-p)
+	for k := 1; k <= n; k++ {
+		logPmf[k] = logPmf[k-1] + math.Log(float64(n-k+1)/float64(k)) + math.Log(p) - math.Log1p(-p)
+	}
+	tail := 0.0
+	for c := n; c >= 0; c-- {
+		tail += math.Exp(logPmf[c])
+		if tail >= alpha {
+			return c + 1
+		}
+	}
+	return 0
+}
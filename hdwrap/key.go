@@ -2,25 +2,74 @@
 // operations for different cryptocurrency keys.
 package hdwrap
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // Supported HD wallet key formats
 const (
-	Bad = iota
+	Bad KeyType = iota
 	Btc
 	Zec
 	Eth
 	Dcr
+	Sol
+	Xlm
+	Ada
+	Cosmos
+	Ltc
+	Doge
 )
 
 // KeyType tracks supported Key formats.
 type KeyType int
 
 var keyTypeMap = map[string]KeyType{
-	"btc": Btc,
-	"zec": Zec,
-	"eth": Eth,
-	"dcr": Dcr,
+	"btc":    Btc,
+	"zec":    Zec,
+	"eth":    Eth,
+	"dcr":    Dcr,
+	"sol":    Sol,
+	"xlm":    Xlm,
+	"ada":    Ada,
+	"cosmos": Cosmos,
+	"ltc":    Ltc,
+	"doge":   Doge,
+}
+
+// KeyTypes returns all the supported KeyTypes.
+func KeyTypes() []KeyType {
+	return []KeyType{Btc, Zec, Eth, Dcr, Sol, Xlm, Ada, Cosmos, Ltc, Doge}
+}
+
+// CoinType returns the SLIP-44 registered coin type for the KeyType,
+// used as second element in BIP44 derivation paths.
+func (t KeyType) CoinType() uint32 {
+	switch t {
+	case Btc:
+		return 0
+	case Zec:
+		return 133
+	case Eth:
+		return 60
+	case Dcr:
+		return 42
+	case Sol:
+		return 501
+	case Xlm:
+		return 148
+	case Ada:
+		return 1815
+	case Cosmos:
+		return 118
+	case Ltc:
+		return 2
+	case Doge:
+		return 3
+	default:
+		panic("bad key type")
+	}
 }
 
 // String returns the string representation of a KeyType
@@ -38,13 +87,26 @@ func (t KeyType) String() string {
 // while sharing the same seed for all HD wallet key formats.
 type Key interface {
 	Type() KeyType
-	SetTestNet(b bool)
+	SetNetwork(n Network) error
 	FromSeed(s Seed) error
 	FromString(data string, priv bool) error
 	GetMasterPub() (string, error)
 	GetMasterPriv() (string, error)
-	GetChildPrivKey(index int) (string, error)
-	GetChildPubKey(index int) (string, error)
+	GetChildPrivKey(p Path) (string, error)
+	GetChildPubKey(p Path) (string, error)
+	GetAccountPub(account uint32) (string, error)
+	AccountPath(account uint32) Path
+	AddressPath(account, change, index uint32) Path
+}
+
+// ErrNoExtendedKeys is returned by key formats which have no extended
+// key encoding, for operations which need one.
+type ErrNoExtendedKeys struct {
+	KeyType KeyType
+}
+
+func (e ErrNoExtendedKeys) Error() string {
+	return fmt.Sprintf("%s keys have no extended key format", e.KeyType)
 }
 
 // EmptyKey returns an unitialized Key given a KeyType.
@@ -58,6 +120,18 @@ func EmptyKey(t KeyType) Key {
 		return &EthKey{}
 	case Dcr:
 		return &DcrKey{}
+	case Sol:
+		return &SolKey{}
+	case Xlm:
+		return &XlmKey{}
+	case Ada:
+		return &AdaKey{}
+	case Cosmos:
+		return &CosmosKey{}
+	case Ltc:
+		return &LtcKey{}
+	case Doge:
+		return &DogeKey{}
 	default:
 		panic("bad key type")
 	}
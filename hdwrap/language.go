@@ -0,0 +1,100 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Supported BIP39 wordlist languages
+const (
+	English Language = iota
+	Spanish
+	French
+	Italian
+	Japanese
+	Korean
+	Czech
+	ChineseSimplified
+	ChineseTraditional
+	Portuguese
+)
+
+// Language identifies one of the official BIP39 wordlists.
+type Language int
+
+var languageMap = map[string]Language{
+	"english":             English,
+	"spanish":             Spanish,
+	"french":              French,
+	"italian":             Italian,
+	"japanese":            Japanese,
+	"korean":              Korean,
+	"czech":               Czech,
+	"chinese-simplified":  ChineseSimplified,
+	"chinese-traditional": ChineseTraditional,
+	"portuguese":          Portuguese,
+}
+
+// ParseLanguage returns the Language for the given name (english,
+// spanish, japanese, chinese-simplified...).
+func ParseLanguage(s string) (Language, error) {
+	l, ok := languageMap[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown language: %s", s)
+	}
+	return l, nil
+}
+
+// String returns the string representation of a Language
+func (l Language) String() string {
+	for k, v := range languageMap {
+		if v == l {
+			return k
+		}
+	}
+	panic("bad language")
+}
+
+// bip39Wordlist holds the words of a language and their indexes,
+// by NFKD-normalized word.
+type bip39Wordlist struct {
+	words []string
+	index map[string]int
+}
+
+var bip39Wordlists = make(map[Language]*bip39Wordlist)
+
+func init() {
+	lists := map[Language][]string{
+		English:            wordlists.English,
+		Spanish:            wordlists.Spanish,
+		French:             wordlists.French,
+		Italian:            wordlists.Italian,
+		Japanese:           wordlists.Japanese,
+		Korean:             wordlists.Korean,
+		Czech:              wordlists.Czech,
+		ChineseSimplified:  wordlists.ChineseSimplified,
+		ChineseTraditional: wordlists.ChineseTraditional,
+		Portuguese:         portugueseWords,
+	}
+
+	for l, words := range lists {
+		wl := &bip39Wordlist{
+			words: words,
+			index: make(map[string]int, len(words)),
+		}
+		for i, w := range words {
+			wl.index[normalizeNFKD(w)] = i
+		}
+		bip39Wordlists[l] = wl
+	}
+}
+
+// normalizeNFKD applies the Unicode NFKD normalization required by
+// BIP39 for mnemonics and passphrases.
+func normalizeNFKD(s string) string {
+	return norm.NFKD.String(s)
+}
@@ -0,0 +1,96 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// LndPurpose is the purpose path element of lnd key derivation paths
+// (m/1017'/coin'/family'/0/index).
+const LndPurpose uint32 = 1017
+
+// lnd key families
+const (
+	LndFamilyMultiSig LndKeyFamily = iota
+	LndFamilyRevocationBase
+	LndFamilyHTLCBase
+	LndFamilyPaymentBase
+	LndFamilyDelayBase
+	LndFamilyRevocationRoot
+	// LndFamilyNodeKey holds the node identity key (index 0).
+	LndFamilyNodeKey
+	// LndFamilyStaticBackup holds the key used to encrypt static
+	// channel backups.
+	LndFamilyStaticBackup
+	LndFamilyTowerSession
+	LndFamilyTowerID
+)
+
+// LndKeyFamily identifies the purpose of the keys derived by lnd.
+type LndKeyFamily uint32
+
+var lndKeyFamilyMap = map[string]LndKeyFamily{
+	"multisig":        LndFamilyMultiSig,
+	"revocation-base": LndFamilyRevocationBase,
+	"htlc-base":       LndFamilyHTLCBase,
+	"payment-base":    LndFamilyPaymentBase,
+	"delay-base":      LndFamilyDelayBase,
+	"revocation-root": LndFamilyRevocationRoot,
+	"node":            LndFamilyNodeKey,
+	"static-backup":   LndFamilyStaticBackup,
+	"tower-session":   LndFamilyTowerSession,
+	"tower-id":        LndFamilyTowerID,
+}
+
+// ParseLndKeyFamily returns the LndKeyFamily for the given name (node,
+// multisig, static-backup...) or number.
+func ParseLndKeyFamily(s string) (LndKeyFamily, error) {
+	if f, ok := lndKeyFamilyMap[strings.ToLower(s)]; ok {
+		return f, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || uint32(n) >= HardenedKeyStart {
+		return 0, fmt.Errorf("unknown lnd key family: %s", s)
+	}
+	return LndKeyFamily(n), nil
+}
+
+// String returns the name of the key family, or its number for
+// families unknown to this package.
+func (f LndKeyFamily) String() string {
+	for k, v := range lndKeyFamilyMap {
+		if v == f {
+			return k
+		}
+	}
+	return strconv.FormatUint(uint64(f), 10)
+}
+
+// LndKeyPath returns the path of the lnd key of the given family and
+// index (m/1017'/coin'/family'/0/index), where coin is 0, or 1 for test
+// networks. The keys must be derived with GetLndPrivKey to match lnd's.
+func (k *BtcKey) LndKeyPath(family LndKeyFamily, index uint32) Path {
+	return AccountPath(LndPurpose, k.params().coinType, uint32(family)).Child(0, index)
+}
+
+// GetLndPrivKey derives the private key of the given lnd family and index.
+//
+// lnd derives its keys through btcwallet, which keeps the non-standard
+// hardened derivation of btcutil before btcsuite/btcutil#172: private
+// keys shorter than 32 bytes are not padded. The same derivation is used
+// here, so that lnd keys match the ones of the node even when they
+// differ from BIP32.
+func (k *BtcKey) GetLndPrivKey(family LndKeyFamily, index uint32) (*btcec.PrivateKey, error) {
+	key := k.key
+	for _, i := range k.LndKeyPath(family, index) {
+		child, err := key.DeriveNonStandard(i)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key.ECPrivKey()
+}
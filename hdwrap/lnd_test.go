@@ -0,0 +1,77 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Node identity keys of lnd (v0.17.0) wallets created from aezeed
+// mnemonics without passphrase. The second one has a private key
+// shorter than 32 bytes at m/1017'/0', so its node key differs from the
+// BIP32 derivation of m/1017'/0'/6'/0/0.
+var lndNodeKeyVectors = []struct {
+	mnemonic string
+	nodeKey  string
+	bip32    bool
+}{
+	{
+		// From lnd's docs/recovery.md.
+		"ability noise lift document certain month shoot perfect matrix mango excess turkey river pitch fluid rack drill text buddy pool soul fatal ship jelly",
+		"02689549a6727a1e6f8d9f2e4bf9d13bd7c3c6cd94ad605233850e040b8959a12d",
+		true,
+	},
+	{
+		"abandon tenant state maze motor sun venture stomach inspire cave unusual scissors alarm oil appear shove brass foil view peace blood castle tragic section",
+		"03d33fef6bf159571c904727dc7390206089c979345abacdf6e1ab42340c992a85",
+		false,
+	},
+}
+
+func TestLndNodeKey(t *testing.T) {
+	for _, v := range lndNodeKeyVectors {
+		sf, err := NewAezeedSeedFile(v.mnemonic, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := &BtcKey{}
+		if err := k.FromSeed(sf.Seed); err != nil {
+			t.Fatal(err)
+		}
+
+		priv, err := k.GetLndPrivKey(LndFamilyNodeKey, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(priv.PubKey().SerializeCompressed()); got != v.nodeKey {
+			t.Errorf("node key %s, expected %s", got, v.nodeKey)
+		}
+
+		pub, err := k.GetChildPubKeyBtc(k.LndKeyPath(LndFamilyNodeKey, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bip32 := hex.EncodeToString(pub.SerializeCompressed()) == v.nodeKey; bip32 != v.bip32 {
+			t.Errorf("%s: BIP32 derivation matches the node key: %t, expected %t", v.nodeKey, bip32, v.bip32)
+		}
+	}
+}
+
+func TestParseLndKeyFamily(t *testing.T) {
+	for s, expected := range map[string]LndKeyFamily{
+		"node":          LndFamilyNodeKey,
+		"static-backup": LndFamilyStaticBackup,
+		"Multisig":      LndFamilyMultiSig,
+		"42":            42,
+	} {
+		f, err := ParseLndKeyFamily(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f != expected {
+			t.Errorf("%s: family %d, expected %d", s, f, expected)
+		}
+	}
+	if _, err := ParseLndKeyFamily("2147483648"); err == nil {
+		t.Error("expected an error for a hardened family number")
+	}
+}
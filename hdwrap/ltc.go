@@ -0,0 +1,80 @@
+package hdwrap
+
+// LtcKey works much like a BtcKey, using the Litecoin network parameters:
+// L... (P2PKH), M... (P2SH-P2WPKH) and ltc1... (P2WPKH) addresses and
+// Ltub/Mtub extended keys.
+type LtcKey struct {
+	key *BtcKey
+}
+
+func (k *LtcKey) Type() KeyType {
+	return Ltc
+}
+
+// btc returns the wrapped BtcKey, initialized with the Litecoin
+// network parameters.
+func (k *LtcKey) btc() *BtcKey {
+	if k.key == nil {
+		k.key = &BtcKey{networks: litecoinNetworks}
+	}
+	return k.key
+}
+
+// SetNetwork allows producing keys for Litecoin's testnet (testnet4 or
+// testnet3) and regtest.
+func (k *LtcKey) SetNetwork(n Network) error {
+	if _, ok := litecoinNetworks[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	return k.btc().SetNetwork(n)
+}
+
+// AddressType returns the type of addresses produced by this key.
+func (k *LtcKey) AddressType() AddressType {
+	return k.btc().AddressType()
+}
+
+// SetAddressType selects the type of addresses produced by this key:
+// P2PKH, P2SH-P2WPKH or P2WPKH.
+func (k *LtcKey) SetAddressType(t AddressType) error {
+	return k.btc().SetAddressType(t)
+}
+
+// FromString imports Litecoin extended keys (Ltub..., Mtub...). The
+// address type is set according to their version.
+func (k *LtcKey) FromString(data string, priv bool) error {
+	return k.btc().FromString(data, priv)
+}
+
+func (k *LtcKey) FromSeed(s Seed) error {
+	return k.btc().FromSeed(s)
+}
+
+func (k *LtcKey) GetMasterPub() (string, error) {
+	return k.key.GetMasterPub()
+}
+
+func (k *LtcKey) GetMasterPriv() (string, error) {
+	return k.key.GetMasterPriv()
+}
+
+func (k *LtcKey) AccountPath(account uint32) Path {
+	return k.key.AccountPath(account)
+}
+
+func (k *LtcKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
+
+func (k *LtcKey) GetAccountPub(account uint32) (string, error) {
+	return k.key.GetExtendedPubKey(k.AccountPath(account))
+}
+
+// GetChildPrivKey returns the WIF-encoded private key (T...).
+func (k *LtcKey) GetChildPrivKey(p Path) (string, error) {
+	return k.key.GetChildPrivKey(p)
+}
+
+func (k *LtcKey) GetChildPubKey(p Path) (string, error) {
+	return k.key.GetChildPubKey(p)
+}
@@ -0,0 +1,139 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// checkBase58Prefix checks that base58check payloads of the given
+// length and version start with one of the given characters.
+func checkBase58Prefix(t *testing.T, version []byte, length int, prefixes string) {
+	t.Helper()
+	for _, fill := range []byte{0x00, 0xff} {
+		payload := make([]byte, length)
+		for i := range payload {
+			payload[i] = fill
+		}
+		s := base58Check(payload, version)
+		if !strings.ContainsAny(s[:1], prefixes) {
+			t.Errorf("version %x: %s does not start with any of %q", version, s, prefixes)
+		}
+	}
+}
+
+// Litecoin keys of the "abandon ... about" mnemonic (account 0, first
+// receive address).
+var ltcVectors = []struct {
+	network    Network
+	addrType   AddressType
+	path       string
+	address    string
+	wif        string
+	accountPub string
+}{
+	{Mainnet, P2PKH, "m/44'/2'/0'/0/0",
+		"LUWPbpM43E2p7ZSh8cyTBEkvpHmr3cB8Ez",
+		"T5b4RiWRs7XG8xZ2bCHBoJcn4JrpMTbGRFYXgoZHd7nD8izwqhMK",
+		"Ltub2YDQmP391UYeDYvLye9P1SuNJFkcRGN7SYHM8JMxaDnegcPTXHJ2BnYmvHnFnGPGKu2WMuCga6iZV3SDxDMGrRyMcrYEfSPhrpS1EPkC43E"},
+	{Mainnet, P2SHP2WPKH, "m/49'/2'/0'/0/0",
+		"M7wtsL7wSHDBJVMWWhtQfTMSYYkyooAAXM",
+		"T8xSEcthDYN4rNUu4eTqtZTDSvphsjgBNbKawBeCkUqZLZ9MH8Ff",
+		"Mtub2rz9F1pkisRsSZX8sa4Ajon9GhPP6JymLgpuHqbYdU5JKFLBF7Qy8b1tZ3dccj2fefrAxfrPdVkpCxuWn3g72UctH2bvJRkp6iFmp8aLeRZ"},
+	{Mainnet, P2WPKH, "m/84'/2'/0'/0/0",
+		"ltc1qjmxnz78nmc8nq77wuxh25n2es7rzm5c2rkk4wh",
+		"T5ZCYhLqXu6EJKk2nhjvwsaLH357CisixhLGWpKXEiqWTUtzte6o",
+		"zpub6rPo5mF47z5coVm5rvWv7fv181awb7Vckn5Cf3xQXBVKu18kuBHDhNi1Jrb4br6vVD3ZbrnXemEsWJoR18mZwkUdzwD8TQnHDUCGxqZ6swA"},
+	{Testnet4, P2PKH, "m/44'/1'/0'/0/0",
+		"mkpZhYtJu2r87Js3pDiWJDmPte2NRZ8bJV",
+		"cV6NTLu255SZ5iCNkVHezNGDH5qv6CanJpgBPqYgJU13NNKJhRs1",
+		"ttub4d4VPcY3DBxKCoAjoeDr9q3FaD4dbY89X65XUhapWiaSFbEjYLwnNg2EcHgEVSEALaEVdZnprREdcWMPJxqFkvN89FcPRFBueauxVCvFpUt"},
+	{Testnet4, P2SHP2WPKH, "m/49'/1'/0'/0/0",
+		"QRHtkDQdVvNNwrVjEdeCGviCw7Ny3SNNiA",
+		"cULrpoZGXiuC19Uhvykx7NugygA3k86b3hmdCeyvHYQZSxojGyXJ",
+		"upub5EFU65HtV5TeiSHmZZm7FUffBGy8UKeqp7vw43jYbvZPpoVsgU93oac7Wk3u6moKegAEWtGNF8DehrnHtv21XXEMYRUocHqguyjknFHYfgY"},
+	{Testnet4, P2WPKH, "m/84'/1'/0'/0/0",
+		"tltc1q6rz28mcfaxtmd6v789l9rrlrusdprr9pesrjxk",
+		"cTGhosGriPpuGA586jemcuH9pE9spwUmneMBmYYzrQEbY92DJrbo",
+		"vpub5Y6cjg78GGuNLsaPhmYsiw4gYX3HoQiRBiSwDaBXKUafCt9bNwWQiitDk5VZ5BVxYnQdwoTyXSs2JHRPAgjAvtbBrf8ZhDYe2jWAqvZVnsc"},
+}
+
+func TestLtcKeys(t *testing.T) {
+	seed, err := hex.DecodeString(bip86Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range ltcVectors {
+		k := &LtcKey{}
+		if err := k.FromSeed(seed); err != nil {
+			t.Fatal(err)
+		}
+		if err := k.SetNetwork(v.network); err != nil {
+			t.Fatal(err)
+		}
+		if err := k.SetAddressType(v.addrType); err != nil {
+			t.Fatal(err)
+		}
+
+		p := k.AddressPath(0, 0, 0)
+		if p.String() != v.path {
+			t.Errorf("%s %s: address path %s, expected %s", v.network, v.addrType, p, v.path)
+		}
+		addr, err := k.GetChildPubKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != v.address {
+			t.Errorf("%s: address %s, expected %s", p, addr, v.address)
+		}
+		wif, err := k.GetChildPrivKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wif != v.wif {
+			t.Errorf("%s: private key %s, expected %s", p, wif, v.wif)
+		}
+		xpub, err := k.GetAccountPub(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xpub != v.accountPub {
+			t.Errorf("%s %s: account key %s, expected %s", v.network, v.addrType, xpub, v.accountPub)
+		}
+
+		// Importing the account key selects the address type.
+		imported := &LtcKey{}
+		if err := imported.FromString(v.accountPub, false); err != nil {
+			t.Fatal(err)
+		}
+		if imported.AddressType() != v.addrType {
+			t.Errorf("%s: imported address type %s, expected %s", v.accountPub, imported.AddressType(), v.addrType)
+		}
+	}
+}
+
+func TestLtcVersions(t *testing.T) {
+	main := litecoinNetworks[Mainnet]
+	checkBase58Prefix(t, main.pubKeyHashAddrID, 20, "L")
+	checkBase58Prefix(t, main.scriptHashAddrID, 20, "M")
+	if main.privateKeyID != 0xb0 {
+		t.Errorf("WIF version %x, expected b0", main.privateKeyID)
+	}
+	checkBase58Prefix(t, []byte{main.privateKeyID}, 33, "T")
+
+	test := litecoinNetworks[Testnet4]
+	checkBase58Prefix(t, test.pubKeyHashAddrID, 20, "mn")
+	checkBase58Prefix(t, test.scriptHashAddrID, 20, "Q")
+	checkBase58Prefix(t, []byte{test.privateKeyID}, 33, "c")
+
+	wif := ltcVectors[0].wif
+	_, version, err := base58.CheckDecode(wif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0xb0 {
+		t.Errorf("%s: version %x, expected b0", wif, version)
+	}
+}
@@ -0,0 +1,64 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported networks
+const (
+	Mainnet Network = iota
+	Testnet3
+	Testnet4
+	Regtest
+	Signet
+	Simnet
+)
+
+// Network identifies the network for which keys and addresses are
+// produced. Not every KeyType supports every Network.
+type Network int
+
+var networkMap = map[string]Network{
+	"mainnet":  Mainnet,
+	"testnet3": Testnet3,
+	"testnet4": Testnet4,
+	"regtest":  Regtest,
+	"signet":   Signet,
+	"simnet":   Simnet,
+}
+
+// ParseNetwork returns the Network for the given name. "testnet" is
+// accepted as an alias for testnet3.
+func ParseNetwork(s string) (Network, error) {
+	s = strings.ToLower(s)
+	if s == "testnet" {
+		return Testnet3, nil
+	}
+	n, ok := networkMap[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown network: %s", s)
+	}
+	return n, nil
+}
+
+// String returns the string representation of a Network
+func (n Network) String() string {
+	for k, v := range networkMap {
+		if v == n {
+			return k
+		}
+	}
+	panic("bad network")
+}
+
+// ErrUnsupportedNetwork is returned when setting a Network which the
+// key format does not support.
+type ErrUnsupportedNetwork struct {
+	KeyType KeyType
+	Network Network
+}
+
+func (e ErrUnsupportedNetwork) Error() string {
+	return fmt.Sprintf("%s keys do not support the %s network", e.KeyType, e.Network)
+}
@@ -0,0 +1,169 @@
+package hdwrap
+
+// btcParams holds the encoding parameters of a Bitcoin-like network,
+// much like chaincfg.Params does, so that BtcKey can produce keys and
+// addresses for other networks and cryptocurrencies.
+type btcParams struct {
+	pubKeyHashAddrID []byte
+	scriptHashAddrID []byte
+	privateKeyID     byte
+	bech32HRP        string
+	coinType         uint32
+	hdVersions       map[AddressType]hdVersions
+}
+
+// btcHDVersions are the extended key versions used on Bitcoin's
+// main network for each address type (SLIP-132).
+var btcHDVersions = map[AddressType]hdVersions{
+	P2PKH:      xpubVersions,
+	P2SHP2WPKH: ypubVersions,
+	P2WPKH:     zpubVersions,
+	P2TR:       xpubVersions,
+	P2WSH:      zpubMultisigVersions,
+}
+
+// btcTestHDVersions are the extended key versions used on Bitcoin's
+// test networks for each address type (SLIP-132).
+var btcTestHDVersions = map[AddressType]hdVersions{
+	P2PKH:      tpubVersions,
+	P2SHP2WPKH: upubVersions,
+	P2WPKH:     vpubVersions,
+	P2TR:       tpubVersions,
+	P2WSH:      vpubMultisigVersions,
+}
+
+var btcTestNetParams = &btcParams{
+	pubKeyHashAddrID: []byte{0x6f},
+	scriptHashAddrID: []byte{0xc4},
+	privateKeyID:     0xef,
+	bech32HRP:        "tb",
+	coinType:         1,
+	hdVersions:       btcTestHDVersions,
+}
+
+// bitcoinNetworks lists the parameters for the supported Bitcoin
+// networks. All test networks share the same encodings except regtest,
+// which uses its own bech32 prefix.
+var bitcoinNetworks = map[Network]*btcParams{
+	Mainnet: {
+		pubKeyHashAddrID: []byte{0x00},
+		scriptHashAddrID: []byte{0x05},
+		privateKeyID:     0x80,
+		bech32HRP:        "bc",
+		coinType:         Btc.CoinType(),
+		hdVersions:       btcHDVersions,
+	},
+	Testnet3: btcTestNetParams,
+	Testnet4: btcTestNetParams,
+	Signet:   btcTestNetParams,
+	Regtest: {
+		pubKeyHashAddrID: []byte{0x6f},
+		scriptHashAddrID: []byte{0xc4},
+		privateKeyID:     0xef,
+		bech32HRP:        "bcrt",
+		coinType:         1,
+		hdVersions:       btcTestHDVersions,
+	},
+}
+
+var zecTestNetParams = &btcParams{
+	pubKeyHashAddrID: ZcashTestnetPrefix,
+	scriptHashAddrID: ZcashTestnetP2SHPrefix,
+	privateKeyID:     0xef,
+	coinType:         1,
+	hdVersions:       map[AddressType]hdVersions{P2PKH: tpubVersions},
+}
+
+// zcashNetworks lists the parameters for the supported Zcash networks
+// (transparent addresses only). Regtest uses the testnet encodings.
+var zcashNetworks = map[Network]*btcParams{
+	Mainnet: {
+		pubKeyHashAddrID: ZcashPrefix,
+		scriptHashAddrID: ZcashP2SHPrefix,
+		privateKeyID:     0x80,
+		coinType:         Zec.CoinType(),
+		hdVersions:       map[AddressType]hdVersions{P2PKH: xpubVersions},
+	},
+	Testnet3: zecTestNetParams,
+	Regtest:  zecTestNetParams,
+}
+
+// ltcHDVersions are the extended key versions used on Litecoin's main
+// network. SLIP-132 registers Ltub and Mtub for P2PKH and P2SH-P2WPKH,
+// but no Litecoin versions for native segwit, so P2WPKH keys use
+// Bitcoin's zpub, which is what Trezor and Electrum-LTC export and
+// import for Litecoin native segwit accounts.
+var ltcHDVersions = map[AddressType]hdVersions{
+	P2PKH:      ltubVersions,
+	P2SHP2WPKH: mtubVersions,
+	P2WPKH:     zpubVersions,
+}
+
+// ltcTestHDVersions are the extended key versions used on Litecoin's
+// test networks.
+var ltcTestHDVersions = map[AddressType]hdVersions{
+	P2PKH:      ttubVersions,
+	P2SHP2WPKH: upubVersions,
+	P2WPKH:     vpubVersions,
+}
+
+var ltcTestNetParams = &btcParams{
+	pubKeyHashAddrID: []byte{0x6f},
+	scriptHashAddrID: []byte{0x3a},
+	privateKeyID:     0xef,
+	bech32HRP:        "tltc",
+	coinType:         1,
+	hdVersions:       ltcTestHDVersions,
+}
+
+// litecoinNetworks lists the parameters for the supported Litecoin
+// networks. Litecoin's current test network is called testnet4, but
+// testnet3 is accepted too.
+var litecoinNetworks = map[Network]*btcParams{
+	Mainnet: {
+		pubKeyHashAddrID: []byte{0x30},
+		scriptHashAddrID: []byte{0x32},
+		privateKeyID:     0xb0,
+		bech32HRP:        "ltc",
+		coinType:         Ltc.CoinType(),
+		hdVersions:       ltcHDVersions,
+	},
+	Testnet3: ltcTestNetParams,
+	Testnet4: ltcTestNetParams,
+	Regtest: {
+		pubKeyHashAddrID: []byte{0x6f},
+		scriptHashAddrID: []byte{0x3a},
+		privateKeyID:     0xef,
+		bech32HRP:        "rltc",
+		coinType:         1,
+		hdVersions:       ltcTestHDVersions,
+	},
+}
+
+// dogecoinNetworks lists the parameters for the supported Dogecoin
+// networks. Dogecoin has no segwit, so only P2PKH addresses and
+// dgub/tgub extended keys are supported. Regtest uses the Bitcoin test
+// network encodings, as Dogecoin Core does.
+var dogecoinNetworks = map[Network]*btcParams{
+	Mainnet: {
+		pubKeyHashAddrID: []byte{0x1e},
+		scriptHashAddrID: []byte{0x16},
+		privateKeyID:     0x9e,
+		coinType:         Doge.CoinType(),
+		hdVersions:       map[AddressType]hdVersions{P2PKH: dgubVersions},
+	},
+	Testnet3: {
+		pubKeyHashAddrID: []byte{0x71},
+		scriptHashAddrID: []byte{0xc4},
+		privateKeyID:     0xf1,
+		coinType:         1,
+		hdVersions:       map[AddressType]hdVersions{P2PKH: tgubVersions},
+	},
+	Regtest: {
+		pubKeyHashAddrID: []byte{0x6f},
+		scriptHashAddrID: []byte{0xc4},
+		privateKeyID:     0xef,
+		coinType:         1,
+		hdVersions:       map[AddressType]hdVersions{P2PKH: tpubVersions},
+	},
+}
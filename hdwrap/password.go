@@ -0,0 +1,113 @@
+package hdwrap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// PasswordFormat identifies the encoding of passwords derived from a
+// seed with BIP85.
+type PasswordFormat string
+
+// Supported password formats
+const (
+	// PasswordBase64 passwords have 20 to 86 characters.
+	PasswordBase64 PasswordFormat = "base64"
+	// PasswordBase85 passwords have 10 to 80 characters.
+	PasswordBase85 PasswordFormat = "base85"
+)
+
+// base85Alphabet is the RFC 1924 base85 character set.
+const base85Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz!#$%&()*+-;<=>?@^_`{|}~"
+
+// ParsePasswordFormat returns the PasswordFormat for the given name
+// (base64 or base85).
+func ParsePasswordFormat(s string) (PasswordFormat, error) {
+	switch f := PasswordFormat(s); f {
+	case PasswordBase64, PasswordBase85:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown password format: %s", s)
+	}
+}
+
+// lengths returns the minimum and maximum lengths of passwords in
+// the format.
+func (f PasswordFormat) lengths() (int, int) {
+	if f == PasswordBase85 {
+		return 10, 80
+	}
+	return 20, 86
+}
+
+// BIP85Password derives a password of the given length and format
+// (BIP85 PWD BASE64 and PWD BASE85 applications).
+func (s Seed) BIP85Password(f PasswordFormat, length int, index uint32) (string, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return "", err
+	}
+	return bip85Password(master, f, length, index)
+}
+
+func bip85Password(master *hdkeychain.ExtendedKey, f PasswordFormat, length int, index uint32) (string, error) {
+	app := BIP85AppPWDBase64
+	if f == PasswordBase85 {
+		app = BIP85AppPWDBase85
+	}
+	min, max := f.lengths()
+	if length < min || length > max {
+		return "", fmt.Errorf("the length of %s passwords must be between %d and %d", f, min, max)
+	}
+
+	entropy, err := bip85Entropy(master, Path{
+		Hardened(app),
+		Hardened(uint32(length)),
+		Hardened(index),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer zero(entropy)
+
+	var pwd string
+	switch f {
+	case PasswordBase64:
+		pwd = base64.StdEncoding.EncodeToString(entropy)
+	case PasswordBase85:
+		pwd = encodeBase85(entropy)
+	default:
+		return "", fmt.Errorf("unknown password format: %s", f)
+	}
+	return pwd[:length], nil
+}
+
+// SitePasswordIndex returns the BIP85 index of the password for a site
+// label: the first 31 bits of sha256("<site>/<counter>"). Increasing the
+// counter produces a new password for the same site.
+func SitePasswordIndex(site string, counter uint32) uint32 {
+	h := sha256.Sum256([]byte(site + "/" + strconv.FormatUint(uint64(counter), 10)))
+	return binary.BigEndian.Uint32(h[:4]) &^ HardenedKeyStart
+}
+
+// encodeBase85 encodes b, whose length must be a multiple of 4, with
+// the RFC 1924 character set.
+func encodeBase85(b []byte) string {
+	out := make([]byte, 0, len(b)/4*5)
+	for i := 0; i+4 <= len(b); i += 4 {
+		v := binary.BigEndian.Uint32(b[i:])
+		var chunk [5]byte
+		for j := 4; j >= 0; j-- {
+			chunk[j] = base85Alphabet[v%85]
+			v /= 85
+		}
+		out = append(out, chunk[:]...)
+	}
+	return string(out)
+}
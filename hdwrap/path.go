@@ -0,0 +1,105 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HardenedKeyStart is the index at which hardened child keys start.
+const HardenedKeyStart uint32 = 0x80000000
+
+// BIP44Purpose is the purpose path element of BIP44 paths.
+const BIP44Purpose uint32 = 44
+
+// A Path is a BIP32 derivation path. Each element is a child index
+// to derive from the previous key, starting with the master key.
+// Indexes >= HardenedKeyStart are hardened.
+type Path []uint32
+
+// Hardened returns the hardened version of the given child index.
+func Hardened(i uint32) uint32 {
+	return i | HardenedKeyStart
+}
+
+// AccountPath returns the BIP44 account-level path
+// m/purpose'/coinType'/account'. All its elements are hardened.
+func AccountPath(purpose, coinType, account uint32) Path {
+	return Path{Hardened(purpose), Hardened(coinType), Hardened(account)}
+}
+
+// ParsePath parses a derivation path in the usual "m/44'/0'/0'/0/5"
+// notation. Hardened indexes can be marked with "'" or "h". The leading "m/"
+// is optional and "m" alone refers to the master key (empty path).
+func ParsePath(s string) (Path, error) {
+	s = strings.TrimSpace(s)
+	if s == "m" || s == "M" {
+		return Path{}, nil
+	}
+	s = strings.TrimPrefix(s, "m/")
+	s = strings.TrimPrefix(s, "M/")
+	if s == "" {
+		return nil, fmt.Errorf("empty derivation path")
+	}
+
+	parts := strings.Split(s, "/")
+	p := make(Path, 0, len(parts))
+	for _, part := range parts {
+		hardened := false
+		switch {
+		case strings.HasSuffix(part, "'"),
+			strings.HasSuffix(part, "h"),
+			strings.HasSuffix(part, "H"):
+			hardened = true
+			part = part[:len(part)-1]
+		}
+		i, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bad path element %q", part)
+		}
+		if uint32(i) >= HardenedKeyStart {
+			return nil, fmt.Errorf("path element %d is out of range", i)
+		}
+		idx := uint32(i)
+		if hardened {
+			idx = Hardened(idx)
+		}
+		p = append(p, idx)
+	}
+	return p, nil
+}
+
+// Child returns a new Path which extends this one with the given
+// indexes.
+func (p Path) Child(indexes ...uint32) Path {
+	child := make(Path, 0, len(p)+len(indexes))
+	child = append(child, p...)
+	return append(child, indexes...)
+}
+
+// IsHardened returns true when any of the path elements is
+// hardened, meaning that the path cannot be derived from a public key.
+func (p Path) IsHardened() bool {
+	for _, i := range p {
+		if i >= HardenedKeyStart {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the path in "m/44'/0'/0'/0/5" notation.
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, i := range p {
+		b.WriteString("/")
+		if i >= HardenedKeyStart {
+			b.WriteString(strconv.FormatUint(uint64(i-HardenedKeyStart), 10))
+			b.WriteString("'")
+			continue
+		}
+		b.WriteString(strconv.FormatUint(uint64(i), 10))
+	}
+	return b.String()
+}
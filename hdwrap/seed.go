@@ -6,10 +6,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
 	"strings"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/decred/dcrwallet/walletseed"
 )
 
@@ -37,25 +37,33 @@ func Generate(randLen int) (Seed, error) {
 // to it and then a SHA512 operation is performed before returning the resulting
 // 64 bytes.
 func GenerateCustom(randLen int, randSource io.Reader, extraRand []byte) (Seed, error) {
-	h := sha512.New()
 	randBytes := make([]byte, randLen)
 	_, err := io.ReadFull(randSource, randBytes)
 	if err != nil {
 		return nil, err
 	}
+	return NewSeedFromRandomness(randBytes, extraRand), nil
+}
+
+// NewSeedFromRandomness produces a 512-bit seed from bytes read from a
+// source of randomness and an additional user-defined slice of bytes,
+// as GenerateCustom does. It allows to check the random bytes (see
+// CheckRandomness) before using them.
+func NewSeedFromRandomness(randBytes, extraRand []byte) Seed {
+	h := sha512.New()
 	h.Write(randBytes)
 	h.Write(extraRand)
-	return h.Sum(nil), nil
+	return h.Sum(nil)
 }
 
-// NewSeedFromFile reads a hex-encoded Seed read from a file.
-func NewSeedFromFile(path string) (Seed, error) {
-	seedHexBytes, err := ioutil.ReadFile(path)
+// NewSeedFromFile reads a Seed from a seed file, decrypting it if
+// needed. See ReadSeedFile.
+func NewSeedFromFile(path string, passphrase PassphraseFunc) (Seed, error) {
+	f, err := ReadSeedFile(path, passphrase)
 	if err != nil {
 		return nil, err
 	}
-
-	return NewSeedFromHex(seedHexBytes)
+	return f.Seed, nil
 }
 
 // NewSeedFromWords decodes a mnemonic-encoded seed. The input
@@ -117,11 +125,18 @@ func (s Seed) Zero() {
 // wordsPerLine specifies how many words should be printed
 // before moving on to a new line.
 func (s Seed) PrintMnemonic(wordsPerLine int) {
+	PrintWords(s.EncodeWords(), wordsPerLine)
+}
+
+// PrintWords prints a mnemonic list of words.
+// wordsPerLine specifies how many words should be printed
+// before moving on to a new line.
+func PrintWords(words []string, wordsPerLine int) {
 	if wordsPerLine <= 0 {
 		panic("wordsPerLine must be >= 1")
 	}
 
-	for i, w := range s.EncodeWords() {
+	for i, w := range words {
 		fmt.Printf("%s", w)
 		if (i+wordsPerLine+1)%wordsPerLine == 0 {
 			fmt.Printf("\n")
@@ -131,13 +146,29 @@ func (s Seed) PrintMnemonic(wordsPerLine int) {
 	}
 }
 
-// WriteToFile writes a the hex-encoded representation of the Seed to a file.
+// WriteToFile writes the Seed to a seed file, as a randomly generated
+// (SchemeRaw) seed. See SeedFile.WriteToFile.
 // It will use 0600 permissions.
 func (s Seed) WriteToFile(path string, overwrite bool) error {
-	// If file does not exist or overwrite is true
-	if _, err := os.Stat(path); os.IsNotExist(err) || overwrite {
-		return ioutil.WriteFile(path, s.EncodeHex(), 0600)
+	f := &SeedFile{
+		Scheme: SchemeRaw,
+		Seed:   s,
 	}
+	return f.WriteToFile(path, overwrite)
+}
 
-	return fmt.Errorf("file %s exists. No ovewrite action will be performed", path)
+// Fingerprint returns the BIP32 fingerprint of the master key derived
+// from the seed: the first 4 bytes of the hash160 of its public key. It
+// identifies the seed in key origin information (i.e. output descriptors
+// and PSBTs).
+func (s Seed) Fingerprint() ([]byte, error) {
+	master, err := hdkeychain.NewMaster(s, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := master.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return hash160(pub.SerializeCompressed())[:4], nil
 }
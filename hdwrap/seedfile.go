@@ -0,0 +1,338 @@
+package hdwrap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SeedFileVersion is the version of the seed file format written by
+// this package. Legacy seed files contain just the hex-encoded seed.
+// Version 1 seed files have a checksum of the seed, the entropy and the
+// mnemonic only, while version 2 checksums cover all the fields.
+const SeedFileVersion = 2
+
+// SeedScheme identifies how a Seed was produced and, therefore, which
+// mnemonic can be used to back it up and recover it.
+type SeedScheme string
+
+// Supported seed schemes
+const (
+	// SchemeRaw seeds are random bytes, which are backed up using the
+	// dcrwallet PGP word list.
+	SchemeRaw SeedScheme = "raw"
+	// SchemeBIP39 seeds are derived from a BIP39 mnemonic and an
+	// optional passphrase.
+	SchemeBIP39 SeedScheme = "bip39"
+	// SchemeElectrum seeds are derived from an Electrum (2.0 or later)
+	// mnemonic and an optional passphrase.
+	SchemeElectrum SeedScheme = "electrum"
+	// SchemeAezeed seeds are the entropy of an lnd aezeed cipher seed,
+	// which is encrypted with an optional passphrase.
+	SchemeAezeed SeedScheme = "aezeed"
+)
+
+// A SeedFile holds a Seed along with information about how it was
+// produced and what it is used for.
+type SeedFile struct {
+	Scheme SeedScheme
+	Seed   Seed
+	// Entropy is the value encoded by the mnemonic, when it is not
+	// the Seed itself (i.e. BIP39).
+	Entropy []byte
+	// Language is the wordlist language of BIP39 mnemonics.
+	Language Language
+	// Phrase is the mnemonic of schemes which cannot encode it from
+	// the Entropy (i.e. Electrum, aezeed).
+	Phrase string
+	// Created is the creation date of the seed, which is the earliest
+	// date wallets need to rescan for transactions. It may be unknown
+	// (zero).
+	Created time.Time
+	// Label is an optional description of the seed.
+	Label string
+}
+
+// seedFileJSON is the seed file container format. Fingerprint and
+// Checksum allow to verify that the seed was not damaged.
+type seedFileJSON struct {
+	Version     int        `json:"version"`
+	Scheme      SeedScheme `json:"scheme"`
+	Created     string     `json:"created,omitempty"`
+	Label       string     `json:"label,omitempty"`
+	Fingerprint string     `json:"fingerprint,omitempty"`
+	Seed        string     `json:"seed"`
+	Entropy     string     `json:"entropy,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Mnemonic    string     `json:"mnemonic,omitempty"`
+	Checksum    string     `json:"checksum,omitempty"`
+}
+
+// NewBIP39SeedFile returns a SeedFile for the BIP39 mnemonic that encodes
+// the given entropy in the given language, deriving the Seed with the
+// given passphrase.
+func NewBIP39SeedFile(entropy []byte, lang Language, passphrase string) (*SeedFile, error) {
+	words, err := EncodeBIP39(entropy, lang)
+	if err != nil {
+		return nil, err
+	}
+	mnemonic := strings.Join(words, " ")
+	return &SeedFile{
+		Scheme:   SchemeBIP39,
+		Seed:     bip39Seed(mnemonic, passphrase),
+		Entropy:  entropy,
+		Language: lang,
+	}, nil
+}
+
+// ReadSeedFile reads a seed file. Legacy seed files, which contain
+// just the hex-encoded seed, are read as SchemeRaw seeds. Encrypted seed
+// files are decrypted with the passphrase returned by the given function,
+// which may be nil when encrypted seed files are not expected.
+func ReadSeedFile(path string, passphrase PassphraseFunc) (*SeedFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isEncryptedSeedData(data) {
+		data, err = decryptSeedData(data, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		defer zero(data)
+	}
+	return parseSeedFile(data)
+}
+
+func parseSeedFile(data []byte) (*SeedFile, error) {
+	var err error
+	data = bytes.TrimSpace(data)
+
+	if !bytes.HasPrefix(data, []byte("{")) {
+		seed, err := NewSeedFromHex(data)
+		if err != nil {
+			return nil, err
+		}
+		return &SeedFile{Scheme: SchemeRaw, Seed: seed}, nil
+	}
+
+	var sfj seedFileJSON
+	if err := json.Unmarshal(data, &sfj); err != nil {
+		return nil, fmt.Errorf("error parsing seed file: %s", err)
+	}
+	if sfj.Version > SeedFileVersion {
+		return nil, fmt.Errorf("unsupported seed file version %d", sfj.Version)
+	}
+
+	f := &SeedFile{Scheme: sfj.Scheme, Label: sfj.Label, Phrase: sfj.Mnemonic}
+	f.Seed, err = hex.DecodeString(sfj.Seed)
+	if err != nil {
+		return nil, err
+	}
+	f.Entropy, err = hex.DecodeString(sfj.Entropy)
+	if err != nil {
+		return nil, err
+	}
+	if sfj.Created != "" {
+		f.Created, err = time.Parse(time.RFC3339, sfj.Created)
+		if err != nil {
+			return nil, fmt.Errorf("bad seed file creation date: %s", err)
+		}
+	}
+
+	// Unversioned JSON seed files have no checksum nor fingerprint.
+	if sfj.Version > 0 {
+		checksum := sfj.checksum()
+		if sfj.Version == 1 {
+			checksum = f.legacyChecksum()
+		}
+		if sfj.Checksum != checksum {
+			return nil, fmt.Errorf("seed file checksum mismatch: the seed file is corrupted")
+		}
+		fp, err := f.Seed.Fingerprint()
+		if err != nil {
+			return nil, err
+		}
+		if sfj.Fingerprint != hex.EncodeToString(fp) {
+			return nil, fmt.Errorf("seed file fingerprint mismatch: the seed file is corrupted")
+		}
+	}
+
+	switch f.Scheme {
+	case SchemeRaw:
+	case SchemeBIP39:
+		if len(f.Entropy) == 0 {
+			return nil, fmt.Errorf("BIP39 seed file has no entropy")
+		}
+		// Seed files without language are English.
+		if sfj.Language != "" {
+			f.Language, err = ParseLanguage(sfj.Language)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case SchemeElectrum:
+		if _, err := ElectrumSeedTypeOf(f.Phrase); err != nil {
+			return nil, fmt.Errorf("bad Electrum seed file mnemonic: %s", err)
+		}
+	case SchemeAezeed:
+		if _, err := aezeedEnciphered(f.Phrase); err != nil {
+			return nil, fmt.Errorf("bad aezeed seed file mnemonic: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown seed scheme: %s", f.Scheme)
+	}
+	return f, nil
+}
+
+// Mnemonic returns the list of words which can be used to recover the
+// seed, according to its scheme.
+func (f *SeedFile) Mnemonic() ([]string, error) {
+	switch f.Scheme {
+	case SchemeRaw:
+		return f.Seed.EncodeWords(), nil
+	case SchemeBIP39:
+		return EncodeBIP39(f.Entropy, f.Language)
+	case SchemeElectrum, SchemeAezeed:
+		return strings.Fields(f.Phrase), nil
+	default:
+		return nil, fmt.Errorf("unknown seed scheme: %s", f.Scheme)
+	}
+}
+
+// Marshal returns the contents of the seed file, in the versioned
+// JSON container format.
+func (f *SeedFile) Marshal() ([]byte, error) {
+	fp, err := f.Seed.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	sfj := seedFileJSON{
+		Version:     SeedFileVersion,
+		Scheme:      f.Scheme,
+		Label:       f.Label,
+		Fingerprint: hex.EncodeToString(fp),
+		Seed:        string(f.Seed.EncodeHex()),
+		Entropy:     hex.EncodeToString(f.Entropy),
+		Mnemonic:    f.Phrase,
+	}
+	if !f.Created.IsZero() {
+		sfj.Created = f.Created.UTC().Format(time.RFC3339)
+	}
+	if f.Scheme == SchemeBIP39 {
+		sfj.Language = f.Language.String()
+	}
+	sfj.Checksum = sfj.checksum()
+	data, err := json.MarshalIndent(sfj, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteToFile writes the seed file. It will use 0600 permissions.
+func (f *SeedFile) WriteToFile(path string, overwrite bool) error {
+	data, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+	return writeSeedFile(path, data, overwrite)
+}
+
+// WriteEncryptedToFile writes the seed file encrypted with
+// XChaCha20-Poly1305, using a key derived from the given passphrase
+// with the given KDF. It will use 0600 permissions.
+func (f *SeedFile) WriteEncryptedToFile(path string, passphrase []byte, kdf KDF, overwrite bool) error {
+	data, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+	defer zero(data)
+
+	encrypted, err := encryptSeedData(data, passphrase, kdf)
+	if err != nil {
+		return err
+	}
+	return writeSeedFile(path, encrypted, overwrite)
+}
+
+// checksum returns the first 4 bytes of the SHA256 hash of the compact
+// JSON serialization of all the fields but the checksum, hex-encoded.
+func (sfj seedFileJSON) checksum() string {
+	sfj.Checksum = ""
+	// Marshaling strings and ints cannot fail.
+	data, _ := json.Marshal(sfj)
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:4])
+}
+
+// legacyChecksum returns the checksum of version 1 seed files: the first
+// 4 bytes of the SHA256 hash of the seed, the entropy and the mnemonic
+// phrase, hex-encoded.
+func (f *SeedFile) legacyChecksum() string {
+	h := sha256.New()
+	h.Write(f.Seed)
+	h.Write(f.Entropy)
+	h.Write([]byte(f.Phrase))
+	return hex.EncodeToString(h.Sum(nil)[:4])
+}
+
+// writeSeedFile writes the seed file atomically: the data is written
+// and synced to a temporary file in the same directory, which is then
+// renamed over the destination. A failure never leaves a truncated seed
+// file behind, which matters when replacing an existing one. When not
+// overwriting, the temporary file is hard-linked to the destination
+// instead, which fails if it exists, so that a file created in the
+// meantime is never replaced.
+func writeSeedFile(path string, data []byte, overwrite bool) error {
+	dir := filepath.Dir(path)
+	// Temporary files are created with 0600 permissions.
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if overwrite {
+		err = os.Rename(tmpPath, path)
+	} else {
+		err = os.Link(tmpPath, path)
+		if os.IsExist(err) {
+			return fmt.Errorf("file %s exists. No ovewrite action will be performed", path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes a directory, so that a rename in it is durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
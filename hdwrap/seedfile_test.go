@@ -0,0 +1,147 @@
+package hdwrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func testSeedFile(t *testing.T) *SeedFile {
+	t.Helper()
+	entropy, err := DecodeBIP39(bip86Mnemonic, English)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err := NewBIP39SeedFile(entropy, English, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.Label = "test"
+	return sf
+}
+
+func TestSeedFileWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mhdw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seed.json")
+
+	sf := testSeedFile(t)
+	if err := sf.WriteToFile(path, false); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		st, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := st.Mode().Perm(); perm != 0600 {
+			t.Errorf("permissions %o, expected 600", perm)
+		}
+	}
+
+	// Existing files are kept unless overwriting.
+	other := &SeedFile{Scheme: SchemeRaw, Seed: bytes.Repeat([]byte{1}, 32)}
+	if err := other.WriteToFile(path, false); err == nil {
+		t.Error("expected an error writing over an existing file")
+	}
+	read, err := ReadSeedFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read.Seed, sf.Seed) {
+		t.Error("the seed file was modified")
+	}
+	checkSeedFileDir(t, dir)
+
+	if err := other.WriteToFile(path, true); err != nil {
+		t.Fatal(err)
+	}
+	read, err = ReadSeedFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read.Seed, other.Seed) {
+		t.Error("the seed file was not overwritten")
+	}
+
+	checkSeedFileDir(t, dir)
+}
+
+// checkSeedFileDir checks that no temporary files are left behind in
+// dir, which must only contain the seed file.
+func checkSeedFileDir(t *testing.T, dir string) {
+	t.Helper()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("%d files in the directory, expected 1", len(files))
+	}
+}
+
+// tamperSeedFile returns the seed file data with the given field set to
+// the given value.
+func tamperSeedFile(t *testing.T, data []byte, field string, value interface{}) []byte {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	m[field] = value
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSeedFileChecksum(t *testing.T) {
+	sf := testSeedFile(t)
+	data, err := sf.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSeedFile(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Any change is detected.
+	tests := []struct {
+		field string
+		value interface{}
+	}{
+		{"version", 1},
+		{"scheme", "raw"},
+		{"created", "2020-01-01T00:00:00Z"},
+		{"label", "other"},
+		{"language", "spanish"},
+		{"mnemonic", "x"},
+	}
+	for _, tc := range tests {
+		if _, err := parseSeedFile(tamperSeedFile(t, data, tc.field, tc.value)); err == nil {
+			t.Errorf("changing %s was not detected", tc.field)
+		}
+	}
+
+	// Version 1 checksums cover the seed, the entropy and the mnemonic.
+	v1 := tamperSeedFile(t, data, "version", 1)
+	v1 = tamperSeedFile(t, v1, "checksum", sf.legacyChecksum())
+	read, err := parseSeedFile(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read.Seed, sf.Seed) || read.Label != sf.Label {
+		t.Error("the version 1 seed file was not read correctly")
+	}
+	if _, err := parseSeedFile(tamperSeedFile(t, v1, "entropy", "00")); err == nil {
+		t.Error("changing the entropy of a version 1 seed file was not detected")
+	}
+}
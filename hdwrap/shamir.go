@@ -0,0 +1,160 @@
+package hdwrap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// Shamir's secret sharing over GF(256), as specified by SLIP-39.
+
+const (
+	shamirSecretIndex = 255
+	shamirDigestIndex = 254
+	shamirDigestLen   = 4
+)
+
+// GF(256) exponent and logarithm tables, using the Rijndael polynomial
+// x^8 + x^4 + x^3 + x + 1 and 3 as generator.
+var gfExp, gfLog = gfTables()
+
+func gfTables() (exp [255]byte, log [256]byte) {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(poly)
+		log[poly] = byte(i)
+		// multiply by 3
+		poly = (poly << 1) ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11b
+		}
+	}
+	return
+}
+
+type shamirShare struct {
+	x     byte
+	value []byte
+}
+
+// shamirInterpolate returns the value at x of the polynomial that goes
+// through the given shares (Lagrange interpolation).
+func shamirInterpolate(shares []shamirShare, x byte) ([]byte, error) {
+	seen := make(map[byte]bool)
+	for _, s := range shares {
+		if seen[s.x] {
+			return nil, fmt.Errorf("share indices must be unique")
+		}
+		seen[s.x] = true
+		if len(s.value) != len(shares[0].value) {
+			return nil, fmt.Errorf("all share values must have the same length")
+		}
+	}
+
+	for _, s := range shares {
+		if s.x == x {
+			return s.value, nil
+		}
+	}
+
+	logProd := 0
+	for _, s := range shares {
+		logProd += int(gfLog[s.x^x])
+	}
+
+	result := make([]byte, len(shares[0].value))
+	for _, s := range shares {
+		logBasis := logProd - int(gfLog[s.x^x])
+		for _, o := range shares {
+			if o.x != s.x {
+				logBasis -= int(gfLog[s.x^o.x])
+			}
+		}
+		logBasis = ((logBasis % 255) + 255) % 255
+
+		for i, v := range s.value {
+			if v != 0 {
+				result[i] ^= gfExp[(int(gfLog[v])+logBasis)%255]
+			}
+		}
+	}
+	return result, nil
+}
+
+// shamirSplit splits a secret into count shares, any threshold of which
+// can recover it. The polynomial includes a digest of the secret so that
+// recovery can be verified.
+func shamirSplit(threshold, count int, secret []byte, rnd io.Reader) ([]shamirShare, error) {
+	if threshold < 1 || threshold > count {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of shares")
+	}
+	if count > 16 {
+		return nil, fmt.Errorf("the number of shares must not exceed 16")
+	}
+
+	var shares []shamirShare
+	if threshold == 1 {
+		for i := 0; i < count; i++ {
+			shares = append(shares, shamirShare{byte(i), secret})
+		}
+		return shares, nil
+	}
+
+	for i := 0; i < threshold-2; i++ {
+		v := make([]byte, len(secret))
+		if _, err := io.ReadFull(rnd, v); err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{byte(i), v})
+	}
+
+	randomPart := make([]byte, len(secret)-shamirDigestLen)
+	if _, err := io.ReadFull(rnd, randomPart); err != nil {
+		return nil, err
+	}
+	digest := append(shamirDigest(randomPart, secret), randomPart...)
+
+	base := append([]shamirShare{}, shares...)
+	base = append(base,
+		shamirShare{shamirDigestIndex, digest},
+		shamirShare{shamirSecretIndex, secret},
+	)
+	for i := threshold - 2; i < count; i++ {
+		v, err := shamirInterpolate(base, byte(i))
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{byte(i), v})
+	}
+	return shares, nil
+}
+
+// shamirRecover recovers the secret from threshold shares and verifies
+// its digest.
+func shamirRecover(threshold int, shares []shamirShare) ([]byte, error) {
+	if threshold == 1 {
+		return shares[0].value, nil
+	}
+
+	secret, err := shamirInterpolate(shares, shamirSecretIndex)
+	if err != nil {
+		return nil, err
+	}
+	digestShare, err := shamirInterpolate(shares, shamirDigestIndex)
+	if err != nil {
+		return nil, err
+	}
+	digest := digestShare[:shamirDigestLen]
+	randomPart := digestShare[shamirDigestLen:]
+	if !hmac.Equal(digest, shamirDigest(randomPart, secret)) {
+		return nil, fmt.Errorf("invalid digest of the shared secret")
+	}
+	return secret, nil
+}
+
+func shamirDigest(randomPart, secret []byte) []byte {
+	h := hmac.New(sha256.New, randomPart)
+	h.Write(secret)
+	return h.Sum(nil)[:shamirDigestLen]
+}
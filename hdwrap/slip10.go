@@ -0,0 +1,229 @@
+package hdwrap
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Curves supported by SLIP-10 derivation
+const (
+	// Ed25519 keys only support hardened derivation.
+	Ed25519 Curve = iota
+	// NIST256P1 is the NIST P-256 (secp256r1) curve.
+	NIST256P1
+)
+
+// Curve identifies the elliptic curve of SLIP-10 keys.
+type Curve int
+
+// String returns the string representation of a Curve
+func (c Curve) String() string {
+	switch c {
+	case Ed25519:
+		return "ed25519"
+	case NIST256P1:
+		return "nist256p1"
+	default:
+		panic("bad curve")
+	}
+}
+
+// hmacKey returns the key used to derive SLIP-10 master keys for the
+// curve.
+func (c Curve) hmacKey() []byte {
+	switch c {
+	case Ed25519:
+		return []byte("ed25519 seed")
+	case NIST256P1:
+		return []byte("Nist256p1 seed")
+	default:
+		panic("bad curve")
+	}
+}
+
+// ErrNonHardenedEd25519 is returned when deriving non-hardened children
+// of ed25519 keys, which SLIP-10 does not support.
+var ErrNonHardenedEd25519 = errors.New("ed25519 keys only support hardened derivation")
+
+// A SLIP10Key is an extended private key derived following SLIP-10,
+// which generalizes BIP32 to other curves than secp256k1. It is the
+// derivation core of Key implementations for those curves.
+type SLIP10Key struct {
+	curve     Curve
+	key       []byte
+	chainCode []byte
+	depth     uint8
+	parentFP  []byte
+	childNum  uint32
+}
+
+// NewSLIP10Master derives the SLIP-10 master key for the given curve
+// from a Seed.
+func NewSLIP10Master(s Seed, c Curve) (*SLIP10Key, error) {
+	if len(s) < 16 || len(s) > 64 {
+		return nil, fmt.Errorf("the seed must be between 16 and 64 bytes")
+	}
+
+	data := []byte(s)
+	for {
+		mac := hmac.New(sha512.New, c.hmacKey())
+		mac.Write(data)
+		i := mac.Sum(nil)
+		il, ir := i[:32], i[32:]
+		// Master keys out of the NIST P-256 range are derived again
+		// from the HMAC output.
+		if c == NIST256P1 && !validP256Key(new(big.Int).SetBytes(il)) {
+			data = i
+			continue
+		}
+		return &SLIP10Key{
+			curve:     c,
+			key:       il,
+			chainCode: ir,
+			parentFP:  []byte{0, 0, 0, 0},
+		}, nil
+	}
+}
+
+// Curve returns the curve of the key.
+func (k *SLIP10Key) Curve() Curve {
+	return k.curve
+}
+
+// Child derives the child key with the given index. Indexes >=
+// HardenedKeyStart are hardened.
+func (k *SLIP10Key) Child(i uint32) (*SLIP10Key, error) {
+	hardened := i >= HardenedKeyStart
+	if k.curve == Ed25519 && !hardened {
+		return nil, ErrNonHardenedEd25519
+	}
+	if k.depth == 255 {
+		return nil, fmt.Errorf("cannot derive beyond depth 255")
+	}
+
+	var data []byte
+	if hardened {
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		data = k.PublicKey()
+	}
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], i)
+
+	for {
+		mac := hmac.New(sha512.New, k.chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		il, ir := sum[:32], sum[32:]
+
+		child := &SLIP10Key{
+			curve:     k.curve,
+			chainCode: ir,
+			depth:     k.depth + 1,
+			parentFP:  k.Fingerprint(),
+			childNum:  i,
+		}
+		if k.curve == Ed25519 {
+			child.key = il
+			return child, nil
+		}
+
+		// childKey = IL + parentKey (mod n). When IL is out of range
+		// or the result is zero, IL and IR are derived again from
+		// 0x01 || IR || index.
+		n := elliptic.P256().Params().N
+		ilNum := new(big.Int).SetBytes(il)
+		childNum := new(big.Int).Add(ilNum, new(big.Int).SetBytes(k.key))
+		childNum.Mod(childNum, n)
+		if ilNum.Cmp(n) >= 0 || childNum.Sign() == 0 {
+			data = append([]byte{0x01}, ir...)
+			data = append(data, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(data[len(data)-4:], i)
+			continue
+		}
+		child.key = paddedBytes(childNum, 32)
+		return child, nil
+	}
+}
+
+// Derive walks the given path starting at this key and returns the
+// resulting key.
+func (k *SLIP10Key) Derive(p Path) (*SLIP10Key, error) {
+	key := k
+	for _, i := range p {
+		child, err := key.Child(i)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// PrivateKey returns the 32-byte private key. For ed25519 keys, it is
+// the RFC 8032 private key seed (see ed25519.NewKeyFromSeed).
+func (k *SLIP10Key) PrivateKey() []byte {
+	return append([]byte{}, k.key...)
+}
+
+// ChainCode returns the chain code of the key.
+func (k *SLIP10Key) ChainCode() []byte {
+	return append([]byte{}, k.chainCode...)
+}
+
+// Depth returns the number of derivations from the master key.
+func (k *SLIP10Key) Depth() uint8 {
+	return k.depth
+}
+
+// ChildNumber returns the index this key was derived with.
+func (k *SLIP10Key) ChildNumber() uint32 {
+	return k.childNum
+}
+
+// ParentFingerprint returns the fingerprint of the parent key, or
+// zeros for master keys.
+func (k *SLIP10Key) ParentFingerprint() []byte {
+	return append([]byte{}, k.parentFP...)
+}
+
+// PublicKey returns the 33-byte public key, as serialized by SLIP-10:
+// compressed points for NIST P-256 and 0x00 followed by the 32-byte
+// public key for ed25519.
+func (k *SLIP10Key) PublicKey() []byte {
+	if k.curve == Ed25519 {
+		return append([]byte{0x00}, k.Ed25519PublicKey()...)
+	}
+	curve := elliptic.P256()
+	x, y := curve.ScalarBaseMult(k.key)
+	return elliptic.MarshalCompressed(curve, x, y)
+}
+
+// Ed25519PublicKey returns the ed25519 public key of ed25519 keys.
+func (k *SLIP10Key) Ed25519PublicKey() ed25519.PublicKey {
+	return ed25519.NewKeyFromSeed(k.key).Public().(ed25519.PublicKey)
+}
+
+// Ed25519PrivateKey returns the ed25519 private key of ed25519 keys,
+// which can be used to sign.
+func (k *SLIP10Key) Ed25519PrivateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(k.key)
+}
+
+// Fingerprint returns the first 4 bytes of the hash160 of the public
+// key, which identifies the key as parent of its children.
+func (k *SLIP10Key) Fingerprint() []byte {
+	return hash160(k.PublicKey())[:4]
+}
+
+// validP256Key returns true when the given number is a valid NIST P-256
+// private key (not zero and lower than the curve order).
+func validP256Key(d *big.Int) bool {
+	return d.Sign() > 0 && d.Cmp(elliptic.P256().Params().N) < 0
+}
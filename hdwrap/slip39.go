@@ -0,0 +1,390 @@
+package hdwrap
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// SLIP-39 constants
+const (
+	slip39RadixBits          = 10
+	slip39IDBits             = 15
+	slip39IterationExpBits   = 4
+	slip39ChecksumWords      = 3
+	slip39MetadataWords      = 7
+	slip39MinWords           = 20
+	slip39BaseIterationCount = 10000
+	slip39RoundCount         = 4
+	slip39MinSecretLen       = 16
+	slip39MaxGroups          = 16
+)
+
+const (
+	slip39CustomizationString           = "shamir"
+	slip39ExtendableCustomizationString = "shamir_extendable"
+)
+
+var slip39Generator = [10]uint32{
+	0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009,
+	0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120,
+}
+
+// SLIP39Group defines how many member shares are created for a group
+// (Count) and how many of them are needed to recover it (Threshold).
+type SLIP39Group struct {
+	Threshold int
+	Count     int
+}
+
+// ParseSLIP39Group parses a group definition in the form
+// "<threshold>-of-<count>" (i.e. "2-of-3").
+func ParseSLIP39Group(s string) (SLIP39Group, error) {
+	var g SLIP39Group
+	_, err := fmt.Sscanf(s, "%d-of-%d", &g.Threshold, &g.Count)
+	if err != nil {
+		return g, fmt.Errorf("bad group %q. Groups are defined as <threshold>-of-<count>", s)
+	}
+	return g, nil
+}
+
+// slip39Share is a decoded SLIP-39 mnemonic share.
+type slip39Share struct {
+	id                uint16
+	extendable        bool
+	iterationExponent int
+	groupIndex        int
+	groupThreshold    int
+	groupCount        int
+	memberIndex       int
+	memberThreshold   int
+	value             []byte
+}
+
+// SplitSLIP39 splits a secret (i.e. a Seed) into SLIP-39 mnemonic shares,
+// returned by group. Any groupThreshold groups are needed to recover the
+// secret, each of them with at least the member threshold of the group
+// shares. The secret is encrypted with the given passphrase, using
+// PBKDF2 with 10000 << iterationExponent iterations.
+func SplitSLIP39(secret []byte, groupThreshold int, groups []SLIP39Group, passphrase string, iterationExponent int) ([][][]string, error) {
+	if len(secret) < slip39MinSecretLen || len(secret)%2 != 0 {
+		return nil, fmt.Errorf("the secret must be at least %d bytes long and have an even length", slip39MinSecretLen)
+	}
+	if iterationExponent < 0 || iterationExponent >= 1<<slip39IterationExpBits {
+		return nil, fmt.Errorf("the iteration exponent must be between 0 and 15")
+	}
+	if len(groups) == 0 || len(groups) > slip39MaxGroups {
+		return nil, fmt.Errorf("between 1 and %d groups are needed", slip39MaxGroups)
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) {
+		return nil, fmt.Errorf("the group threshold must be between 1 and the number of groups")
+	}
+	for _, g := range groups {
+		if g.Threshold == 1 && g.Count > 1 {
+			return nil, fmt.Errorf("creating multiple member shares with member threshold 1 is not allowed. Use 1-of-1 member sharing instead")
+		}
+	}
+	if err := checkSLIP39Passphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	var idBytes [2]byte
+	if _, err := io.ReadFull(rand.Reader, idBytes[:]); err != nil {
+		return nil, err
+	}
+	id := binary.BigEndian.Uint16(idBytes[:]) & (1<<slip39IDBits - 1)
+	// New shares are always extendable.
+	extendable := true
+
+	ems := slip39Encrypt(secret, passphrase, iterationExponent, id, extendable)
+
+	groupShares, err := shamirSplit(groupThreshold, len(groups), ems, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics := make([][][]string, len(groups))
+	for i, g := range groups {
+		memberShares, err := shamirSplit(g.Threshold, g.Count, groupShares[i].value, rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %s", i+1, err)
+		}
+		for _, ms := range memberShares {
+			share := &slip39Share{
+				id:                id,
+				extendable:        extendable,
+				iterationExponent: iterationExponent,
+				groupIndex:        int(groupShares[i].x),
+				groupThreshold:    groupThreshold,
+				groupCount:        len(groups),
+				memberIndex:       int(ms.x),
+				memberThreshold:   g.Threshold,
+				value:             ms.value,
+			}
+			mnemonics[i] = append(mnemonics[i], share.words())
+		}
+	}
+	return mnemonics, nil
+}
+
+// CombineSLIP39 recovers a secret from SLIP-39 mnemonic shares (each of
+// them a space-separated list of words) and decrypts it with the given
+// passphrase. Note that any passphrase produces a valid secret.
+func CombineSLIP39(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, fmt.Errorf("no shares given")
+	}
+	if err := checkSLIP39Passphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	var first *slip39Share
+	groups := make(map[int][]*slip39Share)
+	var groupOrder []int
+	for _, m := range mnemonics {
+		share, err := decodeSLIP39Share(m)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = share
+		}
+		if share.id != first.id || share.extendable != first.extendable ||
+			share.iterationExponent != first.iterationExponent {
+			return nil, fmt.Errorf("all shares must have the same identifier and iteration exponent")
+		}
+		if share.groupThreshold != first.groupThreshold || share.groupCount != first.groupCount {
+			return nil, fmt.Errorf("all shares must have the same group threshold and group count")
+		}
+
+		group := groups[share.groupIndex]
+		for _, s := range group {
+			if s.memberIndex == share.memberIndex {
+				return nil, fmt.Errorf("duplicate share for member %d of group %d", share.memberIndex+1, share.groupIndex+1)
+			}
+			if s.memberThreshold != share.memberThreshold {
+				return nil, fmt.Errorf("all shares of group %d must have the same member threshold", share.groupIndex+1)
+			}
+		}
+		if group == nil {
+			groupOrder = append(groupOrder, share.groupIndex)
+		}
+		groups[share.groupIndex] = append(group, share)
+	}
+
+	var groupShares []shamirShare
+	for _, gi := range groupOrder {
+		group := groups[gi]
+		threshold := group[0].memberThreshold
+		if len(group) < threshold {
+			continue
+		}
+		var memberShares []shamirShare
+		for _, s := range group[:threshold] {
+			memberShares = append(memberShares, shamirShare{byte(s.memberIndex), s.value})
+		}
+		secret, err := shamirRecover(threshold, memberShares)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %s", gi+1, err)
+		}
+		groupShares = append(groupShares, shamirShare{byte(gi), secret})
+	}
+
+	if len(groupShares) < first.groupThreshold {
+		return nil, fmt.Errorf("insufficient shares: recovered %d of the %d required groups", len(groupShares), first.groupThreshold)
+	}
+
+	ems, err := shamirRecover(first.groupThreshold, groupShares[:first.groupThreshold])
+	if err != nil {
+		return nil, err
+	}
+	return slip39Decrypt(ems, passphrase, first.iterationExponent, first.id, first.extendable), nil
+}
+
+// words encodes a share as a list of SLIP-39 words.
+func (s *slip39Share) words() []string {
+	idExp := int(s.id)<<(slip39IterationExpBits+1) | s.iterationExponent
+	if s.extendable {
+		idExp |= 1 << slip39IterationExpBits
+	}
+	params := s.groupIndex<<16 | (s.groupThreshold-1)<<12 | (s.groupCount-1)<<8 |
+		s.memberIndex<<4 | (s.memberThreshold - 1)
+
+	valueWords := (len(s.value)*8 + slip39RadixBits - 1) / slip39RadixBits
+	data := intToSLIP39Indices(big.NewInt(int64(idExp)), 2)
+	data = append(data, intToSLIP39Indices(big.NewInt(int64(params)), 2)...)
+	data = append(data, intToSLIP39Indices(new(big.Int).SetBytes(s.value), valueWords)...)
+	data = append(data, slip39Checksum(data, s.customizationString())...)
+
+	words := make([]string, len(data))
+	for i, d := range data {
+		words[i] = slip39Words[d]
+	}
+	return words
+}
+
+func (s *slip39Share) customizationString() string {
+	if s.extendable {
+		return slip39ExtendableCustomizationString
+	}
+	return slip39CustomizationString
+}
+
+// decodeSLIP39Share parses and validates a SLIP-39 mnemonic.
+func decodeSLIP39Share(mnemonic string) (*slip39Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < slip39MinWords {
+		return nil, fmt.Errorf("invalid SLIP-39 mnemonic length: it must have at least %d words", slip39MinWords)
+	}
+
+	paddingLen := (slip39RadixBits * (len(words) - slip39MetadataWords)) % 16
+	if paddingLen > 8 {
+		return nil, fmt.Errorf("invalid SLIP-39 mnemonic length")
+	}
+
+	data := make([]int, len(words))
+	for i, w := range words {
+		d, ok := slip39WordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("word %q is not in the SLIP-39 wordlist", w)
+		}
+		data[i] = d
+	}
+
+	idExp := data[0]<<slip39RadixBits | data[1]
+	s := &slip39Share{
+		id:                uint16(idExp >> (slip39IterationExpBits + 1)),
+		extendable:        (idExp>>slip39IterationExpBits)&1 == 1,
+		iterationExponent: idExp & (1<<slip39IterationExpBits - 1),
+	}
+
+	if slip39Polymod(data, s.customizationString()) != 1 {
+		return nil, fmt.Errorf("invalid SLIP-39 mnemonic checksum")
+	}
+
+	params := data[2]<<slip39RadixBits | data[3]
+	s.groupIndex = params >> 16
+	s.groupThreshold = (params>>12)&15 + 1
+	s.groupCount = (params>>8)&15 + 1
+	s.memberIndex = (params >> 4) & 15
+	s.memberThreshold = params&15 + 1
+	if s.groupCount < s.groupThreshold {
+		return nil, fmt.Errorf("invalid SLIP-39 mnemonic: group threshold cannot be greater than group count")
+	}
+
+	valueData := data[4 : len(data)-slip39ChecksumWords]
+	if valueData[0] >= 1<<uint(slip39RadixBits-paddingLen) {
+		return nil, fmt.Errorf("invalid SLIP-39 mnemonic padding")
+	}
+	value := new(big.Int)
+	for _, d := range valueData {
+		value.Lsh(value, slip39RadixBits)
+		value.Or(value, big.NewInt(int64(d)))
+	}
+	valueLen := (slip39RadixBits*len(valueData) - paddingLen) / 8
+	if valueLen < slip39MinSecretLen || valueLen%2 != 0 {
+		return nil, fmt.Errorf("invalid SLIP-39 mnemonic: bad secret length")
+	}
+	s.value = paddedBytes(value, valueLen)
+	return s, nil
+}
+
+func intToSLIP39Indices(n *big.Int, length int) []int {
+	indices := make([]int, length)
+	mask := big.NewInt(1<<slip39RadixBits - 1)
+	v := new(big.Int).Set(n)
+	idx := new(big.Int)
+	for i := length - 1; i >= 0; i-- {
+		idx.And(v, mask)
+		indices[i] = int(idx.Int64())
+		v.Rsh(v, slip39RadixBits)
+	}
+	return indices
+}
+
+// slip39Polymod computes the RS1024 checksum of the given data.
+func slip39Polymod(data []int, customization string) uint32 {
+	values := make([]uint32, 0, len(customization)+len(data))
+	for _, c := range []byte(customization) {
+		values = append(values, uint32(c))
+	}
+	for _, d := range data {
+		values = append(values, uint32(d))
+	}
+
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ v
+		for i := 0; i < 10; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= slip39Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func slip39Checksum(data []int, customization string) []int {
+	values := append(append([]int{}, data...), 0, 0, 0)
+	chk := slip39Polymod(values, customization) ^ 1
+	return []int{int(chk>>20) & 1023, int(chk>>10) & 1023, int(chk) & 1023}
+}
+
+func checkSLIP39Passphrase(passphrase string) error {
+	for _, c := range passphrase {
+		if c < 32 || c > 126 {
+			return fmt.Errorf("SLIP-39 passphrases must only contain printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+// slip39Encrypt encrypts the master secret with a 4-round Feistel
+// network, using PBKDF2-HMAC-SHA256 as round function.
+func slip39Encrypt(secret []byte, passphrase string, iterationExponent int, id uint16, extendable bool) []byte {
+	l := secret[:len(secret)/2]
+	r := secret[len(secret)/2:]
+	salt := slip39Salt(id, extendable)
+	for i := 0; i < slip39RoundCount; i++ {
+		l, r = r, xorBytes(l, slip39RoundFunction(i, passphrase, iterationExponent, salt, r))
+	}
+	return append(append([]byte{}, r...), l...)
+}
+
+func slip39Decrypt(ems []byte, passphrase string, iterationExponent int, id uint16, extendable bool) []byte {
+	l := ems[:len(ems)/2]
+	r := ems[len(ems)/2:]
+	salt := slip39Salt(id, extendable)
+	for i := slip39RoundCount - 1; i >= 0; i-- {
+		l, r = r, xorBytes(l, slip39RoundFunction(i, passphrase, iterationExponent, salt, r))
+	}
+	return append(append([]byte{}, r...), l...)
+}
+
+func slip39RoundFunction(i int, passphrase string, iterationExponent int, salt, r []byte) []byte {
+	iterations := (slip39BaseIterationCount << uint(iterationExponent)) / slip39RoundCount
+	password := append([]byte{byte(i)}, passphrase...)
+	return pbkdf2.Key(password, append(append([]byte{}, salt...), r...), iterations, len(r), sha256.New)
+}
+
+func slip39Salt(id uint16, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+	return append([]byte(slip39CustomizationString), byte(id>>8), byte(id))
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
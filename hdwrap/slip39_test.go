@@ -0,0 +1,190 @@
+package hdwrap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+)
+
+// TestSLIP39Vectors uses the SLIP-39 test vectors
+// (https://github.com/satoshilabs/slips/blob/master/slip-0039/vectors.json):
+// a description, the mnemonics, the master secret and the BIP32 master
+// key for it. Invalid sets of mnemonics have no master secret.
+func TestSLIP39Vectors(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/slip39_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors [][]interface{}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no test vectors")
+	}
+
+	for _, v := range vectors {
+		desc := v[0].(string)
+		var mnemonics []string
+		for _, m := range v[1].([]interface{}) {
+			mnemonics = append(mnemonics, m.(string))
+		}
+		secretHex := v[2].(string)
+		xprv := v[3].(string)
+
+		secret, err := CombineSLIP39(mnemonics, "TREZOR")
+		if secretHex == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error", desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", desc, err)
+			continue
+		}
+		if got := hex.EncodeToString(secret); got != secretHex {
+			t.Errorf("%s: master secret %s, expected %s", desc, got, secretHex)
+			continue
+		}
+		master, err := hdkeychain.NewMaster(secret, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if master.String() != xprv {
+			t.Errorf("%s: master key %s, expected %s", desc, master, xprv)
+		}
+	}
+}
+
+func TestSLIP39SplitCombine(t *testing.T) {
+	secret, _ := hex.DecodeString("bb54aac4b89dc868ba37d9cc21b2cece")
+	groups := []SLIP39Group{{1, 1}, {2, 3}, {3, 5}}
+	shares, err := SplitSLIP39(secret, 2, groups, "TREZOR", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != len(groups) {
+		t.Fatalf("got %d groups, expected %d", len(shares), len(groups))
+	}
+	for i, g := range groups {
+		if len(shares[i]) != g.Count {
+			t.Fatalf("group %d has %d shares, expected %d", i+1, len(shares[i]), g.Count)
+		}
+	}
+
+	join := func(words ...[]string) []string {
+		var mnemonics []string
+		for _, w := range words {
+			mnemonics = append(mnemonics, strings.Join(w, " "))
+		}
+		return mnemonics
+	}
+
+	tests := []struct {
+		name      string
+		mnemonics []string
+		ok        bool
+	}{
+		{"groups 1 and 2", join(shares[0][0], shares[1][2], shares[1][0]), true},
+		{"groups 2 and 3", join(shares[2][4], shares[1][1], shares[2][0], shares[2][2], shares[1][2]), true},
+		{"one group", join(shares[1][0], shares[1][1]), false},
+		{"not enough members", join(shares[0][0], shares[2][0], shares[2][1]), false},
+	}
+	for _, tc := range tests {
+		got, err := CombineSLIP39(tc.mnemonics, "TREZOR")
+		if !tc.ok {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("%s: recovered %x, expected %x", tc.name, got, secret)
+		}
+	}
+
+	// A different passphrase produces a different secret.
+	got, err := CombineSLIP39(join(shares[0][0], shares[1][0], shares[1][1]), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, secret) {
+		t.Error("the passphrase was ignored")
+	}
+}
+
+func TestSLIP39Checksum(t *testing.T) {
+	// First mnemonic of the test vectors.
+	words := strings.Fields("duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard")
+	if _, err := decodeSLIP39Share(strings.Join(words, " ")); err != nil {
+		t.Fatal(err)
+	}
+
+	// RS1024 detects any error affecting up to 3 words.
+	for i := range words {
+		w := append([]string{}, words...)
+		w[i] = slip39Words[(slip39WordIndex[w[i]]+1)%len(slip39Words)]
+		if _, err := decodeSLIP39Share(strings.Join(w, " ")); err == nil {
+			t.Errorf("changing word %d was not detected", i+1)
+		}
+	}
+}
+
+func TestSLIP39Feistel(t *testing.T) {
+	secret, _ := hex.DecodeString("bb54aac4b89dc868ba37d9cc21b2cece")
+	for _, extendable := range []bool{false, true} {
+		ems := slip39Encrypt(secret, "TREZOR", 1, 7945, extendable)
+		if bytes.Equal(ems, secret) {
+			t.Fatal("the secret was not encrypted")
+		}
+		if got := slip39Decrypt(ems, "TREZOR", 1, 7945, extendable); !bytes.Equal(got, secret) {
+			t.Errorf("decrypted %x, expected %x", got, secret)
+		}
+		// Only non-extendable shares use the identifier as salt.
+		got := slip39Decrypt(ems, "TREZOR", 1, 7946, extendable)
+		if bytes.Equal(got, secret) != extendable {
+			t.Errorf("extendable: %t: wrong use of the identifier in the salt", extendable)
+		}
+	}
+}
+
+func TestGF256(t *testing.T) {
+	seen := make(map[byte]bool)
+	for i, e := range gfExp {
+		if e == 0 || seen[e] {
+			t.Fatalf("3 is not a generator: 3^%d = %d", i, e)
+		}
+		seen[e] = true
+		if int(gfLog[e]) != i {
+			t.Errorf("log(3^%d) = %d", i, gfLog[e])
+		}
+	}
+
+	// Any threshold shares recover the secret and the digest.
+	secret := []byte("0123456789abcdef")
+	shares, err := shamirSplit(3, 5, secret, bytes.NewReader(bytes.Repeat([]byte{0x5a, 0xc3, 0x17}, 100)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := shamirRecover(3, []shamirShare{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("recovered %x, expected %x", got, secret)
+	}
+	if _, err := shamirRecover(3, []shamirShare{shares[4], shares[0], shares[0]}); err == nil {
+		t.Error("expected an error for duplicate shares")
+	}
+}
@@ -0,0 +1,94 @@
+package hdwrap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// SolKey implements the Key interface for Solana, using SLIP-10
+// ed25519 keys. Addresses are the base58-encoded public keys.
+type SolKey struct {
+	key *SLIP10Key
+}
+
+func (k *SolKey) Type() KeyType {
+	return Sol
+}
+
+// SetNetwork has no effect: Solana keys and addresses are the same in
+// all clusters (mainnet-beta, testnet, devnet).
+func (k *SolKey) SetNetwork(n Network) error {
+	return nil
+}
+
+// FromString is not supported, as Solana has no extended key format.
+func (k *SolKey) FromString(data string, priv bool) error {
+	return ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+func (k *SolKey) FromSeed(s Seed) error {
+	master, err := NewSLIP10Master(s, Ed25519)
+	if err != nil {
+		return err
+	}
+	k.key = master
+	return nil
+}
+
+func (k *SolKey) GetMasterPub() (string, error) {
+	return "", ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+func (k *SolKey) GetMasterPriv() (string, error) {
+	return "", ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+// AccountPath returns m/44'/501'/account'.
+func (k *SolKey) AccountPath(account uint32) Path {
+	return AccountPath(BIP44Purpose, Sol.CoinType(), account)
+}
+
+// AddressPath returns m/44'/501'/account'/change', the path used by
+// Solana wallets, which have a single address per account. All elements
+// are hardened, as ed25519 keys only support hardened derivation. A
+// non-zero index is appended as a further hardened element.
+func (k *SolKey) AddressPath(account, change, index uint32) Path {
+	p := k.AccountPath(account).Child(Hardened(change))
+	if index != 0 {
+		p = p.Child(Hardened(index))
+	}
+	return p
+}
+
+func (k *SolKey) GetAccountPub(account uint32) (string, error) {
+	return "", ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+// GetChildPrivKey derivates a private key and returns it as a Solana
+// CLI keypair file: a JSON array with the 64 bytes of the ed25519
+// private key (seed followed by public key).
+func (k *SolKey) GetChildPrivKey(p Path) (string, error) {
+	child, err := k.key.Derive(p)
+	if err != nil {
+		return "", err
+	}
+	priv := child.Ed25519PrivateKey()
+	defer zero(priv)
+
+	nums := make([]string, len(priv))
+	for i, b := range priv {
+		nums[i] = fmt.Sprint(b)
+	}
+	return "[" + strings.Join(nums, ",") + "]", nil
+}
+
+// GetChildPubKey derivates a public key and returns its Solana address.
+func (k *SolKey) GetChildPubKey(p Path) (string, error) {
+	child, err := k.key.Derive(p)
+	if err != nil {
+		return "", err
+	}
+	return base58.Encode(child.Ed25519PublicKey()), nil
+}
@@ -0,0 +1,71 @@
+package hdwrap
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// Solana keypair of the "abandon ... about" mnemonic at m/44'/501'/0'/0',
+// as given by Phantom and solana-keygen.
+const (
+	solTestAddress = "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"
+	solTestSecret  = "37df573b3ac4ad5b522e064e25b63ea16bcbe79d449e81a0268d1047948bb445"
+)
+
+func TestSolKeypair(t *testing.T) {
+	seed, err := hex.DecodeString(bip86Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &SolKey{}
+	if err := k.FromSeed(seed); err != nil {
+		t.Fatal(err)
+	}
+	p := k.AddressPath(0, 0, 0)
+	if p.String() != "m/44'/501'/0'/0'" {
+		t.Fatalf("unexpected address path %s", p)
+	}
+
+	addr, err := k.GetChildPubKey(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != solTestAddress {
+		t.Errorf("address %s, expected %s", addr, solTestAddress)
+	}
+
+	keypair, err := k.GetChildPrivKey(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var priv []byte
+	var nums []int
+	if err := json.Unmarshal([]byte(keypair), &nums); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range nums {
+		if n < 0 || n > 255 {
+			t.Fatalf("keypair byte out of range: %d", n)
+		}
+		priv = append(priv, byte(n))
+	}
+	if len(priv) != ed25519.PrivateKeySize {
+		t.Fatalf("keypair has %d bytes, expected %d", len(priv), ed25519.PrivateKeySize)
+	}
+
+	// The keypair is the secret key followed by the public key.
+	if got := hex.EncodeToString(priv[:32]); got != solTestSecret {
+		t.Errorf("secret key %s, expected %s", got, solTestSecret)
+	}
+	if got := base58.Encode(priv[32:]); got != solTestAddress {
+		t.Errorf("keypair public key %s, expected %s", got, solTestAddress)
+	}
+	if !bytes.Equal(ed25519.NewKeyFromSeed(priv[:32]), priv) {
+		t.Error("keypair is not a valid ed25519 private key")
+	}
+}
@@ -0,0 +1,116 @@
+package hdwrap
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// taggedHash implements the BIP340 tagged hash:
+// SHA256(SHA256(tag) || SHA256(tag) || msg).
+func taggedHash(tag string, msg ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// taprootTweak returns the BIP341 tweak for a key-path only output
+// with the given x-only internal key (no script tree).
+func taprootTweak(internalX []byte) *big.Int {
+	return new(big.Int).SetBytes(taggedHash("TapTweak", internalX))
+}
+
+// taprootOutputKey returns the x-only BIP341 output key
+// Q = P + hash_TapTweak(P)G, where P is the given public key with
+// an even Y coordinate.
+func taprootOutputKey(k *btcec.PublicKey) []byte {
+	curve := btcec.S256()
+	px := k.X
+	py := new(big.Int).Set(k.Y)
+	if py.Bit(0) == 1 {
+		py.Sub(curve.P, py)
+	}
+
+	pxBytes := paddedBytes(px, 32)
+	t := taprootTweak(pxBytes)
+	tx, ty := curve.ScalarBaseMult(paddedBytes(t, 32))
+	qx, _ := curve.Add(px, py, tx, ty)
+	return paddedBytes(qx, 32)
+}
+
+// encodeTaprootPubkey returns the bech32m-encoded P2TR address for
+// the key-path only output of the given internal key (BIP86).
+func encodeTaprootPubkey(k *btcec.PublicKey, hrp string) (string, error) {
+	return encodeSegwitAddress(hrp, 1, taprootOutputKey(k))
+}
+
+func paddedBytes(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
+
+const descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+	"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+	"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+var descriptorGenerator = [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+func descriptorPolymod(symbols []uint64) uint64 {
+	chk := uint64(1)
+	for _, v := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ v
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= descriptorGenerator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// descriptorWithChecksum appends the BIP380 checksum to an output
+// script descriptor, as required by Bitcoin Core's importdescriptors.
+func descriptorWithChecksum(desc string) string {
+	var symbols, groups []uint64
+	for _, c := range desc {
+		v := strings.IndexRune(descriptorInputCharset, c)
+		if v < 0 {
+			panic("invalid descriptor character")
+		}
+		symbols = append(symbols, uint64(v&31))
+		groups = append(groups, uint64(v>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	symbols = append(symbols, 0, 0, 0, 0, 0, 0, 0, 0)
+	chk := descriptorPolymod(symbols) ^ 1
+
+	var b strings.Builder
+	b.WriteString(desc)
+	b.WriteString("#")
+	for i := 0; i < 8; i++ {
+		b.WriteByte(bech32Charset[(chk>>uint(5*(7-i)))&31])
+	}
+	return b.String()
+}
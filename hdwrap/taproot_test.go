@@ -0,0 +1,117 @@
+package hdwrap
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// BIP86 test vectors, for the "abandon ... about" mnemonic, whose BIP39
+// seed is bip86Seed.
+const (
+	bip86Mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	bip86Seed     = "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"
+)
+
+var bip86Vectors = []struct {
+	change, index uint32
+	internalKey   string
+	outputKey     string
+	address       string
+}{
+	{0, 0,
+		"cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115",
+		"a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c",
+		"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	{0, 1,
+		"83dfe85a3151d2517290da461fe2815591ef69f2b18a2ce63f01697a8b313145",
+		"a82f29944d65b86ae6b5e5cc75e294ead6c59391a1edc5e016e3498c67fc7bbb",
+		"bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh"},
+	{1, 0,
+		"399f1b2f4393f29a18c937859c5dd8a77350103157eb880f02e8c08214277cef",
+		"882d74e5d0572d5a816cef0041a96b6c1de832f6f9676d9605c44d5e9a97d3dc",
+		"bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7"},
+}
+
+func TestTaprootOutputKey(t *testing.T) {
+	for _, v := range bip86Vectors {
+		x, err := hex.DecodeString(v.internalKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Both Y coordinates of the internal key give the same output.
+		for _, prefix := range []byte{0x02, 0x03} {
+			pub, err := btcec.ParsePubKey(append([]byte{prefix}, x...), btcec.S256())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(taprootOutputKey(pub)); got != v.outputKey {
+				t.Errorf("%s: output key %s, expected %s", v.internalKey, got, v.outputKey)
+			}
+		}
+	}
+}
+
+func TestBIP86(t *testing.T) {
+	seed, err := hex.DecodeString(bip86Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &BtcKey{}
+	if err := k.FromSeed(seed); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.SetAddressType(P2TR); err != nil {
+		t.Fatal(err)
+	}
+
+	xpub, err := k.GetAccountPub(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"; xpub != expected {
+		t.Errorf("account key %s, expected %s", xpub, expected)
+	}
+
+	for _, v := range bip86Vectors {
+		p := k.AddressPath(0, v.change, v.index)
+		addr, err := k.GetChildPubKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != v.address {
+			t.Errorf("%s: %s, expected %s", p, addr, v.address)
+		}
+		pub, err := k.GetChildPubKeyBtc(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(pub.SerializeCompressed()[1:]); got != v.internalKey {
+			t.Errorf("%s: internal key %s, expected %s", p, got, v.internalKey)
+		}
+	}
+
+	priv, err := k.GetChildPrivKey(k.AddressPath(0, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "tr(KyRv5iFPHG7iB5E4CqvMzH3WFJVhbfYK4VY7XAedd9Ys69mEsPLQ)#cvd87dq6"; priv != expected {
+		t.Errorf("private key %s, expected %s", priv, expected)
+	}
+}
+
+// Descriptor checksums from BIP380 and Bitcoin Core.
+func TestDescriptorChecksum(t *testing.T) {
+	tests := []struct {
+		desc, expected string
+	}{
+		{"raw(deadbeef)", "raw(deadbeef)#89f8spxm"},
+		{"addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)", "addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)#02wpgw69"},
+	}
+	for _, tc := range tests {
+		if got := descriptorWithChecksum(tc.desc); got != tc.expected {
+			t.Errorf("%s, expected %s", got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package hdwrap
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+)
+
+// StrKey version bytes of Stellar public keys (G...) and secret seeds
+// (S...).
+const (
+	xlmAccountIDVersion  byte = 6 << 3
+	xlmSecretSeedVersion byte = 18 << 3
+)
+
+// XlmKey implements the Key interface for Stellar, following SEP-0005:
+// SLIP-10 ed25519 keys at m/44'/148'/account'.
+type XlmKey struct {
+	key *SLIP10Key
+}
+
+func (k *XlmKey) Type() KeyType {
+	return Xlm
+}
+
+// SetNetwork has no effect: Stellar keys are the same in all networks.
+func (k *XlmKey) SetNetwork(n Network) error {
+	return nil
+}
+
+// FromString is not supported, as Stellar has no extended key format.
+func (k *XlmKey) FromString(data string, priv bool) error {
+	return ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+func (k *XlmKey) FromSeed(s Seed) error {
+	master, err := NewSLIP10Master(s, Ed25519)
+	if err != nil {
+		return err
+	}
+	k.key = master
+	return nil
+}
+
+func (k *XlmKey) GetMasterPub() (string, error) {
+	return "", ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+func (k *XlmKey) GetMasterPriv() (string, error) {
+	return "", ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+// AccountPath returns m/44'/148'/account', which is the path of the
+// keys of Stellar accounts (SEP-0005).
+func (k *XlmKey) AccountPath(account uint32) Path {
+	return AccountPath(BIP44Purpose, Xlm.CoinType(), account)
+}
+
+// AddressPath returns the account path, as Stellar accounts have a
+// single key. Non-zero change and index values are appended as hardened
+// elements (m/44'/148'/account'/change'/index').
+func (k *XlmKey) AddressPath(account, change, index uint32) Path {
+	p := k.AccountPath(account)
+	if change != 0 || index != 0 {
+		p = p.Child(Hardened(change), Hardened(index))
+	}
+	return p
+}
+
+func (k *XlmKey) GetAccountPub(account uint32) (string, error) {
+	return "", ErrNoExtendedKeys{KeyType: k.Type()}
+}
+
+// GetChildPrivKey derivates a private key and returns its StrKey
+// encoded secret seed (S...).
+func (k *XlmKey) GetChildPrivKey(p Path) (string, error) {
+	child, err := k.key.Derive(p)
+	if err != nil {
+		return "", err
+	}
+	return encodeStrKey(xlmSecretSeedVersion, child.PrivateKey()), nil
+}
+
+// GetChildPubKey derivates a public key and returns its StrKey encoded
+// account ID (G...).
+func (k *XlmKey) GetChildPubKey(p Path) (string, error) {
+	child, err := k.key.Derive(p)
+	if err != nil {
+		return "", err
+	}
+	return encodeStrKey(xlmAccountIDVersion, child.Ed25519PublicKey()), nil
+}
+
+// encodeStrKey encodes data with the Stellar StrKey format: base32 of
+// the version byte, the data and a CRC16-XModem checksum (little
+// endian).
+func encodeStrKey(version byte, data []byte) string {
+	payload := append([]byte{version}, data...)
+	payload = append(payload, 0, 0)
+	binary.LittleEndian.PutUint16(payload[len(payload)-2:], crc16XModem(payload[:len(payload)-2]))
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(payload)
+}
+
+// crc16XModem returns the CRC16-XModem checksum (polynomial 0x1021,
+// initial value 0) of b.
+func crc16XModem(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
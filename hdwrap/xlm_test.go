@@ -0,0 +1,57 @@
+package hdwrap
+
+import "testing"
+
+// SEP-0005 test vectors
+// (https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0005.md).
+var sep5Vectors = []struct {
+	mnemonic string
+	account  uint32
+	pub      string
+	priv     string
+}{
+	{"illness spike retreat truth genius clock brain pass fit cave bargain toe", 0,
+		"GDRXE2BQUC3AZNPVFSCEZ76NJ3WWL25FYFK6RGZGIEKWE4SOOHSUJUJ6",
+		"SBGWSG6BTNCKCOB3DIFBGCVMUPQFYPA2G4O34RMTB343OYPXU5DJDVMN"},
+	{"illness spike retreat truth genius clock brain pass fit cave bargain toe", 1,
+		"GBAW5XGWORWVFE2XTJYDTLDHXTY2Q2MO73HYCGB3XMFMQ562Q2W2GJQX",
+		"SCEPFFWGAG5P2VX5DHIYK3XEMZYLTYWIPWYEKXFHSK25RVMIUNJ7CTIS"},
+	{bip86Mnemonic, 0,
+		"GB3JDWCQJCWMJ3IILWIGDTQJJC5567PGVEVXSCVPEQOTDN64VJBDQBYX",
+		"SBUV3MRWKNS6AYKZ6E6MOUVF2OYMON3MIUASWL3JLY5E3ISDJFELYBRZ"},
+	{bip86Mnemonic, 1,
+		"GDVSYYTUAJ3ACHTPQNSTQBDQ4LDHQCMNY4FCEQH5TJUMSSLWQSTG42MV",
+		"SCHDCVCWGAKGIMTORV6K5DYYV3BY4WG3RA4M6MCBGJLHUCWU2MC6DL66"},
+}
+
+func TestSEP5(t *testing.T) {
+	for _, v := range sep5Vectors {
+		seed, err := NewSeedFromBIP39(v.mnemonic, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		k := &XlmKey{}
+		if err := k.FromSeed(seed); err != nil {
+			t.Fatal(err)
+		}
+		p := k.AddressPath(v.account, 0, 0)
+		if expected := k.AccountPath(v.account); p.String() != expected.String() {
+			t.Fatalf("address path %s, expected %s", p, expected)
+		}
+
+		pub, err := k.GetChildPubKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pub != v.pub {
+			t.Errorf("%s: public key %s, expected %s", p, pub, v.pub)
+		}
+		priv, err := k.GetChildPrivKey(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv != v.priv {
+			t.Errorf("%s: secret seed %s, expected %s", p, priv, v.priv)
+		}
+	}
+}
@@ -1,35 +1,44 @@
 package hdwrap
 
-import "github.com/btcsuite/btcd/btcec"
-
 var ZcashPrefix = []byte{0x1c, 0xb8}
 var ZcashP2SHPrefix = []byte{0x1c, 0xbd}
 var ZcashTestnetPrefix = []byte{0x1d, 0x25}
 var ZcashTestnetP2SHPrefix = []byte{0x1c, 0xba}
 
-// ZecKey works much like a BtcKey except for public address generation
+// ZecKey works much like a BtcKey except for public address generation,
+// which uses the Zcash network parameters (transparent addresses).
 type ZecKey struct {
-	key     *BtcKey
-	testnet bool
+	key *BtcKey
 }
 
 func (k *ZecKey) Type() KeyType {
 	return Zec
 }
 
-func (k *ZecKey) SetTestNet(b bool) {
-	k.testnet = b
-	k.key.SetTestNet(b)
+// btc returns the wrapped BtcKey, initialized with the Zcash
+// network parameters.
+func (k *ZecKey) btc() *BtcKey {
+	if k.key == nil {
+		k.key = &BtcKey{networks: zcashNetworks}
+	}
+	return k.key
+}
+
+// SetNetwork allows producing keys for Zcash's testnet
+// (testnet3) and regtest.
+func (k *ZecKey) SetNetwork(n Network) error {
+	if _, ok := zcashNetworks[n]; !ok {
+		return ErrUnsupportedNetwork{KeyType: k.Type(), Network: n}
+	}
+	return k.btc().SetNetwork(n)
 }
 
 func (k *ZecKey) FromString(data string, priv bool) error {
-	k.key = &BtcKey{}
-	return k.key.FromString(data, priv)
+	return k.btc().FromString(data, priv)
 }
 
 func (k *ZecKey) FromSeed(s Seed) error {
-	k.key = &BtcKey{}
-	return k.key.FromSeed(s)
+	return k.btc().FromSeed(s)
 }
 
 func (k *ZecKey) GetMasterPub() (string, error) {
@@ -40,23 +49,22 @@ func (k *ZecKey) GetMasterPriv() (string, error) {
 	return k.key.GetMasterPriv()
 }
 
-func (k *ZecKey) GetChildPrivKey(index int) (string, error) {
-	return k.key.GetChildPrivKey(index)
+func (k *ZecKey) AccountPath(account uint32) Path {
+	return k.key.AccountPath(account)
 }
 
-func (k *ZecKey) GetChildPubKey(index int) (string, error) {
-	ecpub, err := k.key.GetChildPubKeyBtc(index)
-	if err != nil {
-		return "", err
-	}
+func (k *ZecKey) AddressPath(account, change, index uint32) Path {
+	return k.AccountPath(account).Child(change, index)
+}
 
-	if k.testnet {
-		return encodeZcashPubkey(ecpub, ZcashTestnetPrefix), nil
-	}
+func (k *ZecKey) GetAccountPub(account uint32) (string, error) {
+	return k.key.GetExtendedPubKey(k.AccountPath(account))
+}
 
-	return encodeZcashPubkey(ecpub, ZcashPrefix), nil
+func (k *ZecKey) GetChildPrivKey(p Path) (string, error) {
+	return k.key.GetChildPrivKey(p)
 }
 
-func encodeZcashPubkey(k *btcec.PublicKey, prefix []byte) string {
-	return encodeBitcoinPubkey(k, prefix)
+func (k *ZecKey) GetChildPubKey(p Path) (string, error) {
+	return k.key.GetChildPubKey(p)
 }
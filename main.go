@@ -1,17 +1,19 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/rand"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hsanjuan/mhdw/hdwrap"
 	cli "github.com/urfave/cli"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 const defaultSeed = "seed.hex"
@@ -24,10 +26,92 @@ var seedFlag = cli.StringFlag{
 
 var formatFlag = cli.StringFlag{
 	Name:  "format",
-	Usage: "output format: btc, zec, eth or dcr",
+	Usage: "output format: btc, zec, eth, dcr, sol, xlm, ada, cosmos, ltc or doge",
 	Value: "btc",
 }
 
+var pathFlag = cli.StringFlag{
+	Name:  "path",
+	Usage: "full derivation path (i.e. m/44'/0'/0'/0/5) instead of an index",
+}
+
+var addrTypeFlag = cli.StringFlag{
+	Name:  "addrtype",
+	Usage: "address type for btc and ltc: p2pkh (legacy), p2sh-p2wpkh (nested segwit), p2wpkh (native segwit) or p2tr (taproot); for ada: base, enterprise or reward",
+}
+
+var hrpFlag = cli.StringFlag{
+	Name:  "hrp",
+	Usage: "bech32 address prefix for cosmos: cosmos, osmo, juno...",
+	Value: hdwrap.DefaultCosmosHRP,
+}
+
+var networkFlag = cli.StringFlag{
+	Name:  "network",
+	Usage: "network: mainnet, testnet3, testnet4, regtest, signet or simnet",
+	Value: "mainnet",
+}
+
+// testnetFlag is kept for backwards compatibility. It is equivalent to
+// --network testnet3.
+var testnetFlag = cli.BoolFlag{
+	Name:   "testnet",
+	Usage:  "produce keys for testnet usage",
+	Hidden: true,
+}
+
+var accountFlag = cli.UintFlag{
+	Name:  "account",
+	Usage: "BIP44 account number",
+}
+
+var changeFlag = cli.UintFlag{
+	Name:  "change",
+	Usage: "BIP44 change: 0 for receiving addresses, 1 for change addresses",
+}
+
+var schemeFlag = cli.StringFlag{
+	Name:  "scheme",
+	Usage: "seed scheme: raw (dcrwallet word list), bip39, aezeed (lnd) or electrum (decode-words only)",
+	Value: string(hdwrap.SchemeRaw),
+}
+
+var passphraseFlag = cli.StringFlag{
+	Name:  "passphrase",
+	Usage: "optional BIP39, aezeed or Electrum passphrase",
+}
+
+var languageFlag = cli.StringFlag{
+	Name:  "language",
+	Usage: "BIP39 wordlist language: english, spanish, french, italian, japanese, korean, czech, chinese-simplified, chinese-traditional, portuguese",
+}
+
+var encryptFlag = cli.BoolFlag{
+	Name:  "encrypt",
+	Usage: "encrypt the seed file with a passphrase (prompted)",
+}
+
+var kdfFlag = cli.StringFlag{
+	Name:  "kdf",
+	Usage: "key derivation function for encrypted seed files: argon2id or scrypt",
+	Value: string(hdwrap.KDFArgon2id),
+}
+
+var labelFlag = cli.StringFlag{
+	Name:  "label",
+	Usage: "optional description of the seed, stored in the seed file",
+}
+
+var createdFlag = cli.StringFlag{
+	Name:  "created",
+	Usage: "creation date of the seed (YYYY-MM-DD), used by wallets as rescan start",
+}
+
+var indexFlag = cli.UintFlag{
+	Name:  "index",
+	Usage: "BIP44 address index",
+}
+
 func main() {
 	app := cli.NewApp()
 	app.Usage = "A command line utility for manipulating HD wallet keys"
@@ -36,6 +120,9 @@ func main() {
 		seedCmd,
 		privKeyCmd,
 		pubKeyCmd,
+		accountCmd,
+		lndCmd,
+		passwordCmd,
 	}
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -50,6 +137,11 @@ var seedCmd = cli.Command{
 		genSeedCmd,
 		decodeSeedCmd,
 		encodeSeedCmd,
+		splitSeedCmd,
+		combineSeedCmd,
+		encryptSeedCmd,
+		seedInfoCmd,
+		deriveChildCmd,
 	},
 }
 
@@ -63,10 +155,32 @@ for different cryptocurrencies and further derivate addresses and private keys.
 You can include any random input as an argument to the command. This will be
 used along with the values provided by the randomness source.
 
+The bytes read from an alternative randomness source (--randsrc) are checked
+with a set of health tests (repetition count, adaptive proportion, chi-square
+and min-entropy estimate, as in NIST SP 800-90B). Sources that fail them are
+refused, unless --force is given.
+
 The generated seed in a new file. A mnemonic 65-word representation of the seed
 will be printed out and can be used for offline backup. This list of words
 can be converted into a seed file again witht he "seed decode-words <words>"
 command.
+
+With "--scheme bip39", a BIP39 mnemonic of --words words (12, 15, 18, 21 or 24)
+is generated instead, and the seed is derived from it and the optional
+--passphrase. BIP39 mnemonics can be imported by most wallets. The passphrase
+is not stored and is needed, along with the words, to recover the seed.
+Mnemonics are English unless a different --language is given.
+
+With "--scheme aezeed", a 24-word lnd aezeed mnemonic is generated instead. It
+encodes 128 bits of entropy and the creation date of the seed, encrypted with
+the optional --passphrase.
+
+Physical entropy can be provided with "--dice d6", "--dice d20" or "--coins".
+The results are read from the standard input (interactively when it is a
+terminal): enough of them to provide the entropy of the seed (256 bits, or the
+BIP39 or aezeed entropy size) are required, and a warning is printed when they look
+biased. They are mixed with the randomness source, unless --no-rng is given,
+in which case the seed is built purely from them.
 `,
 	ArgsUsage: "<additional randomness>",
 	Flags: []cli.Flag{
@@ -101,10 +215,87 @@ command.
 			Usage: "Mnemonic words per line",
 			Value: 6,
 		},
+		schemeFlag,
+		cli.IntFlag{
+			Name:  "words",
+			Usage: "number of words of BIP39 mnemonics",
+			Value: 24,
+		},
+		passphraseFlag,
+		languageFlag,
+		labelFlag,
+		encryptFlag,
+		kdfFlag,
+		cli.StringFlag{
+			Name:  "dice",
+			Usage: "read dice rolls from the standard input: d6 or d20",
+		},
+		cli.BoolFlag{
+			Name:  "coins",
+			Usage: "read coin flips (h/t) from the standard input",
+		},
+		cli.BoolFlag{
+			Name:  "no-rng",
+			Usage: "build the seed only from dice or coins, without the randomness source",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "use a --randsrc which fails the health tests",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		var r io.Reader = rand.Reader
 		q := c.Bool("quiet")
+
+		scheme := hdwrap.SeedScheme(c.String("scheme"))
+		if scheme == hdwrap.SchemeRaw && c.IsSet("passphrase") {
+			return fmt.Errorf("--passphrase cannot be used with --scheme raw")
+		}
+		entropyBits := 256
+		if scheme == hdwrap.SchemeBIP39 {
+			entLen, err := hdwrap.BIP39EntropyLen(c.Int("words"))
+			if err != nil {
+				return err
+			}
+			entropyBits = entLen * 8
+		}
+		if scheme == hdwrap.SchemeAezeed {
+			entropyBits = hdwrap.AezeedEntropySize * 8
+		}
+
+		var physical []byte
+		var die hdwrap.Die
+		switch {
+		case c.IsSet("dice") && c.Bool("coins"):
+			return fmt.Errorf("--dice and --coins cannot be used together")
+		case c.IsSet("dice"):
+			d, err := hdwrap.ParseDie(c.String("dice"))
+			if err != nil {
+				return err
+			}
+			die = d
+		case c.Bool("coins"):
+			die = hdwrap.Coin
+		}
+		if die != 0 {
+			rolls, err := readRolls(die, entropyBits)
+			if err != nil {
+				return err
+			}
+			physical = hdwrap.EncodeRolls(rolls)
+		}
+
+		randLen := c.Int("randbytes")
+		if c.Bool("no-rng") {
+			if physical == nil {
+				return fmt.Errorf("--no-rng requires --dice or --coins")
+			}
+			if c.IsSet("randsrc") {
+				return fmt.Errorf("--no-rng and --randsrc cannot be used together")
+			}
+			randLen = 0
+		}
+
 		if randsrc := c.String("randsrc"); randsrc != "" {
 			fi, err := os.Open(randsrc)
 			if err != nil {
@@ -115,14 +306,70 @@ command.
 		}
 
 		userRandom := bytes.NewBufferString(strings.Join(c.Args(), ""))
+		userRandom.Write(physical)
+
+		randBytes := make([]byte, randLen)
+		_, err := io.ReadFull(r, randBytes)
+		if err != nil {
+			return fmt.Errorf("error generating seed: %s", err)
+		}
+
+		// Alternative randomness sources are not trusted blindly.
+		if c.IsSet("randsrc") {
+			report := hdwrap.CheckRandomness(randBytes)
+			if !q || !report.Passed() {
+				fmt.Fprintln(os.Stderr, report)
+			}
+			if !report.Passed() {
+				if !c.Bool("force") {
+					return fmt.Errorf("the randomness source failed the health tests. Use --force to use it anyway")
+				}
+				fmt.Fprintln(os.Stderr, "WARNING: using a randomness source which failed the health tests.")
+			}
+		}
+
+		seed := hdwrap.NewSeedFromRandomness(randBytes, userRandom.Bytes())
+
+		var sf *hdwrap.SeedFile
+		switch scheme {
+		case hdwrap.SchemeRaw:
+			sf = &hdwrap.SeedFile{Scheme: hdwrap.SchemeRaw, Seed: seed}
+		case hdwrap.SchemeBIP39:
+			var err error
+			lang := hdwrap.English
+			if c.IsSet("language") {
+				lang, err = hdwrap.ParseLanguage(c.String("language"))
+				if err != nil {
+					return err
+				}
+			}
+			sf, err = hdwrap.NewBIP39SeedFile(seed[:entropyBits/8], lang, c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+		case hdwrap.SchemeAezeed:
+			a := &hdwrap.Aezeed{Birthday: time.Now(), Entropy: seed[:entropyBits/8]}
+			words, err := hdwrap.EncodeAezeed(a, c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+			sf, err = hdwrap.NewAezeedSeedFile(strings.Join(words, " "), c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown seed scheme: %s", c.String("scheme"))
+		}
+
+		sf.Created = time.Now()
 
-		seed, err := hdwrap.GenerateCustom(c.Int("randbytes"), r, userRandom.Bytes())
+		words, err := sf.Mnemonic()
 		if err != nil {
-			return fmt.Errorf("error generating seed: %s, err")
+			return err
 		}
 
 		output := c.String("output")
-		err = seed.WriteToFile(output, c.Bool("overwrite"))
+		err = writeSeedFile(c, sf, output)
 		if err != nil {
 			return err
 		}
@@ -142,13 +389,13 @@ private key and your HD wallets. Keep it safe!`)
 
 			fmt.Println(`
 -------------------------------------------------------------------------`)
-			seed.PrintMnemonic(c.Int("words-per-line"))
+			hdwrap.PrintWords(words, c.Int("words-per-line"))
 
 			fmt.Println(`
 -------------------------------------------------------------------------`)
 			fmt.Println()
 		} else {
-			seed.PrintMnemonic(c.Int("words-per-line"))
+			hdwrap.PrintWords(words, c.Int("words-per-line"))
 		}
 		return nil
 	},
@@ -160,6 +407,21 @@ var decodeSeedCmd = cli.Command{
 	Description: `
 This commands writes a seed file given a valid list of words representing the
 nmemonic encoding of the original seed.
+
+With "--scheme bip39", the words are a BIP39 mnemonic. Its checksum is
+verified and the seed is derived from it and the optional --passphrase.
+The language of the mnemonic is detected automatically, unless --language
+is given.
+
+With "--scheme electrum", the words are an Electrum (2.0 or later) seed. Its
+version is verified and the seed is derived from it and the optional
+--passphrase. Bitcoin keys derived from the seed file follow the paths and
+address type of the Electrum wallet (m/ for standard wallets and m/0' for
+segwit ones).
+
+With "--scheme aezeed", the words are an lnd aezeed mnemonic, which is
+decrypted with the optional --passphrase. Its birthday is used as creation
+date of the seed.
 `,
 	ArgsUsage: "<33 words...>",
 	Flags: []cli.Flag{
@@ -172,15 +434,65 @@ nmemonic encoding of the original seed.
 			Name:  "overwrite",
 			Usage: "replace any existing seed files",
 		},
+		schemeFlag,
+		passphraseFlag,
+		languageFlag,
+		labelFlag,
+		createdFlag,
+		encryptFlag,
+		kdfFlag,
 	},
 	Action: func(c *cli.Context) error {
 		words := strings.Join(c.Args(), " ")
-		seed, err := hdwrap.NewSeedFromWords(words)
-		if err != nil {
-			return err
+
+		var sf *hdwrap.SeedFile
+		switch hdwrap.SeedScheme(c.String("scheme")) {
+		case hdwrap.SchemeRaw:
+			if c.IsSet("passphrase") {
+				return fmt.Errorf("--passphrase cannot be used with --scheme raw")
+			}
+			seed, err := hdwrap.NewSeedFromWords(words)
+			if err != nil {
+				return err
+			}
+			sf = &hdwrap.SeedFile{Scheme: hdwrap.SchemeRaw, Seed: seed}
+		case hdwrap.SchemeBIP39:
+			var lang hdwrap.Language
+			var err error
+			if c.IsSet("language") {
+				lang, err = hdwrap.ParseLanguage(c.String("language"))
+			} else {
+				lang, err = hdwrap.DetectBIP39Language(words)
+			}
+			if err != nil {
+				return err
+			}
+			entropy, err := hdwrap.DecodeBIP39(words, lang)
+			if err != nil {
+				return err
+			}
+			sf, err = hdwrap.NewBIP39SeedFile(entropy, lang, c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+		case hdwrap.SchemeElectrum:
+			var err error
+			sf, err = hdwrap.NewElectrumSeedFile(words, c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+		case hdwrap.SchemeAezeed:
+			var err error
+			sf, err = hdwrap.NewAezeedSeedFile(words, c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown seed scheme: %s", c.String("scheme"))
 		}
+
 		output := c.String("output")
-		err = seed.WriteToFile(output, c.Bool("overwrite"))
+		err := writeSeedFile(c, sf, output)
 		if err != nil {
 			return err
 		}
@@ -194,7 +506,8 @@ var encodeSeedCmd = cli.Command{
 	Usage: "print the mnemonic representation of a seed",
 	Description: `
 This commands prints a mnemonic representation of a seed. This is a list of
-64 words plus an extra one which acts as a checksum.
+64 words plus an extra one which acts as a checksum. For seeds generated from
+BIP39 mnemonics, the BIP39 words are printed.
 
 It can be done to easily backup a key on paper, for example.
 `,
@@ -208,205 +521,569 @@ It can be done to easily backup a key on paper, for example.
 		},
 	},
 	Action: func(c *cli.Context) error {
-		seed, err := hdwrap.NewSeedFromFile(c.String("seed"))
+		sf, err := hdwrap.ReadSeedFile(c.String("seed"), seedPassphrase)
+		if err != nil {
+			return err
+		}
+		words, err := sf.Mnemonic()
 		if err != nil {
 			return err
 		}
-		seed.PrintMnemonic(c.Int("words-per-line"))
+		hdwrap.PrintWords(words, c.Int("words-per-line"))
 		return nil
 	},
 }
 
-var privKeyCmd = cli.Command{
-	Name:  "priv",
-	Usage: "tools for working with HD master/derived private keys",
-	Subcommands: []cli.Command{
-		getMasterPrivCmd,
-		getChildPrivKeyCmd,
-	},
-}
-
-var getMasterPrivCmd = cli.Command{
-	Name:  "getmasterpriv",
-	Usage: "create the a master private key from the given seed",
+var splitSeedCmd = cli.Command{
+	Name:  "split",
+	Usage: "split a seed into Shamir backup shares",
 	Description: `
-This command prints an HD wallet private key given a generation seed. The
-output is specific to the chosen cryptocurrency and may not be compatible
-among different ones.
+This command splits a seed into SLIP-39 mnemonic shares, so that it can be
+recovered from a subset of them with "seed combine", but not from any single
+share.
 
-Given the same seed and output format, the result is always the same.
+Shares are organized in groups, defined with --group <threshold>-of-<count>
+(i.e. "--group 2-of-3 --group 3-of-5"). Each group has <count> member shares,
+of which <threshold> are needed to recover the group. --group-threshold groups
+are needed to recover the seed.
+
+Only raw seeds can be split. The shares hold the seed bytes only, so BIP39,
+Electrum and aezeed seeds would lose the mnemonic, which some keys are derived
+from, and must be backed up as mnemonics instead.
+
+The seed is encrypted with the optional --passphrase, which is needed to
+recover it. Note that any passphrase recovers a valid seed, but only the
+original one recovers the right seed. --iteration-exponent makes the
+encryption slower (10000 << exponent PBKDF2 iterations).
 `,
 	ArgsUsage: " ",
 	Flags: []cli.Flag{
 		seedFlag,
-		formatFlag,
-		cli.BoolFlag{
-			Name:  "testnet",
-			Usage: "produce keyout for testnet usage",
+		cli.StringFlag{
+			Name:  "scheme",
+			Usage: "sharing scheme: slip39",
+			Value: "slip39",
+		},
+		cli.IntFlag{
+			Name:  "group-threshold",
+			Usage: "number of groups needed to recover the seed",
+			Value: 1,
+		},
+		cli.StringSliceFlag{
+			Name:  "group",
+			Usage: "group definition as <threshold>-of-<count>. Can be repeated",
+		},
+		cli.StringFlag{
+			Name:  "passphrase",
+			Usage: "optional SLIP-39 passphrase (printable ASCII)",
+		},
+		cli.IntFlag{
+			Name:  "iteration-exponent",
+			Usage: "SLIP-39 iteration exponent (0-15)",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name:  "words-per-line",
+			Usage: "number of words to print per line",
+			Value: 6,
 		},
 	},
 	Action: func(c *cli.Context) error {
-		format := c.String("format")
+		if c.String("scheme") != "slip39" {
+			return fmt.Errorf("unknown sharing scheme: %s", c.String("scheme"))
+		}
 
-		k, err := makeKeyFromSeed(
-			format, c.String("seed"), c.Bool("testnet"))
+		var groups []hdwrap.SLIP39Group
+		for _, g := range c.StringSlice("group") {
+			group, err := hdwrap.ParseSLIP39Group(g)
+			if err != nil {
+				return err
+			}
+			groups = append(groups, group)
+		}
+		if len(groups) == 0 {
+			return fmt.Errorf("at least one --group must be given")
+		}
+
+		sf, err := hdwrap.ReadSeedFile(c.String("seed"), seedPassphrase)
 		if err != nil {
 			return err
 		}
+		if sf.Scheme != hdwrap.SchemeRaw {
+			return fmt.Errorf("only raw seeds can be split: the shares would not keep the %s mnemonic, which is needed to derive the same keys. Back up the mnemonic instead", sf.Scheme)
+		}
 
-		masterpriv, err := k.GetMasterPriv()
+		shares, err := hdwrap.SplitSLIP39(
+			sf.Seed,
+			c.Int("group-threshold"),
+			groups,
+			c.String("passphrase"),
+			c.Int("iteration-exponent"),
+		)
 		if err != nil {
 			return err
 		}
-		fmt.Print(masterpriv)
+
+		wordsPerLine := c.Int("words-per-line")
+		fmt.Printf("%d of %d groups are needed to recover the seed.\n", c.Int("group-threshold"), len(groups))
+		for i, group := range shares {
+			fmt.Printf("\nGroup %d (%d of %d shares needed):\n", i+1, groups[i].Threshold, groups[i].Count)
+			for j, share := range group {
+				fmt.Printf("\nShare %d:\n", j+1)
+				hdwrap.PrintWords(share, wordsPerLine)
+				if len(share)%wordsPerLine != 0 {
+					fmt.Println()
+				}
+			}
+		}
 		return nil
 	},
 }
 
-var getChildPrivKeyCmd = cli.Command{
-	Name:  "child",
-	Usage: "derive a child private key",
+var combineSeedCmd = cli.Command{
+	Name:  "combine",
+	Usage: "recover a seed from Shamir backup shares",
 	Description: `
-This command derives a child private key from the given seed and format
-and prints it out. This can be imported into the different
-cryptocurrency wallets. See the README for more information.
+This command recovers a seed from SLIP-39 mnemonic shares, as produced by
+"seed split", and writes it to a seed file.
+
+Each argument is a share (a quoted list of words). When no arguments are
+given, the shares are read from the standard input, one per line.
 `,
-	ArgsUsage: "<index>",
+	ArgsUsage: "<share> [<share>...]",
 	Flags: []cli.Flag{
-		seedFlag,
-		formatFlag,
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "name of output seed file",
+			Value: defaultSeed,
+		},
 		cli.BoolFlag{
-			Name:  "testnet",
-			Usage: "produce keys for testnet usage",
+			Name:  "overwrite",
+			Usage: "replace any existing seed files",
+		},
+		cli.StringFlag{
+			Name:  "passphrase",
+			Usage: "optional SLIP-39 passphrase",
 		},
+		labelFlag,
+		createdFlag,
+		encryptFlag,
+		kdfFlag,
 	},
 	Action: func(c *cli.Context) error {
-		format := c.String("format")
-
-		if len(c.Args()) != 1 {
-			return fmt.Errorf("must pass the derivation index")
-		}
-
-		i, err := strconv.Atoi(c.Args().First())
-		if err != nil {
-			return err
+		shares := []string(c.Args())
+		if len(shares) == 0 {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); line != "" {
+					shares = append(shares, line)
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return err
+			}
 		}
 
-		k, err := makeKeyFromSeed(
-			format, c.String("seed"), c.Bool("testnet"))
+		secret, err := hdwrap.CombineSLIP39(shares, c.String("passphrase"))
 		if err != nil {
 			return err
 		}
 
-		childpriv, err := k.GetChildPrivKey(i)
+		output := c.String("output")
+		sf := &hdwrap.SeedFile{Scheme: hdwrap.SchemeRaw, Seed: secret}
+		err = writeSeedFile(c, sf, output)
 		if err != nil {
 			return err
 		}
-
-		fmt.Println(childpriv)
+		fmt.Println("Seed correctly recovered to", output)
 		return nil
 	},
 }
 
-var pubKeyCmd = cli.Command{
-	Name:  "pub",
-	Usage: "tools for working with HD public keys and addresses",
-	Subcommands: []cli.Command{
-		getMasterPubCmd,
-		getChildPubKeyCmd,
-	},
-}
-
-var getMasterPubCmd = cli.Command{
-	Name:  "getmasterpub",
-	Usage: "obtain a master public key from the given seed",
+var encryptSeedCmd = cli.Command{
+	Name:  "encrypt",
+	Usage: "encrypt a seed file with a passphrase",
 	Description: `
-This command prints an HD wallet public key given the key generation seed. The
-output is specific to the chosen cryptocurrency and may not be compatible
-among different ones.
+This command encrypts an existing seed file in place, with a key derived from
+a passphrase, which is prompted for. Encrypted seed files can be used with
+any command and the passphrase is prompted for when needed.
 
-Given the same seed and output format, the result is always the same.
+Seed files can be encrypted at creation time too, using the --encrypt flag.
+Encrypting an already encrypted seed file changes its passphrase.
 `,
 	ArgsUsage: " ",
 	Flags: []cli.Flag{
 		seedFlag,
-		formatFlag,
-		cli.BoolFlag{
-			Name:  "testnet",
-			Usage: "produce key for testnet usage",
-		},
+		kdfFlag,
 	},
 	Action: func(c *cli.Context) error {
-		format := c.String("format")
-
-		k, err := makeKeyFromSeed(format, c.String("seed"), c.Bool("testnet"))
+		kdf, err := hdwrap.ParseKDF(c.String("kdf"))
 		if err != nil {
 			return err
 		}
+		path := c.String("seed")
+		sf, err := hdwrap.ReadSeedFile(path, seedPassphrase)
+		if err != nil {
+			return err
+		}
+		defer sf.Seed.Zero()
 
-		masterpub, err := k.GetMasterPub()
+		pass, err := newPassphrase("seed file")
 		if err != nil {
 			return err
 		}
-		fmt.Print(masterpub)
+		err = sf.WriteEncryptedToFile(path, pass, kdf, true)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Seed file", path, "encrypted")
 		return nil
 	},
 }
 
-var getChildPubKeyCmd = cli.Command{
-	Name:  "child",
-	Usage: "derive a child public key",
+var seedInfoCmd = cli.Command{
+	Name:  "info",
+	Usage: "print information identifying a seed",
 	Description: `
-This command derives a child public key and formats it as a payment address.
-
-The command can take a -seed (default) or a -pubkey argument. When providing a
-master public key, it should be formatted for the desired cryptocurrency.
-Otherwise, the generated address will not work.
-
-Given the same seed or public key, the same derivation index and format,
-the resulting address is always the same.
+This command prints the metadata of a seed file, the BIP32 fingerprint of the
+master key derived from the seed and the master public key for every key
+format. They can be used to find out which seed a given public key comes from.
 `,
-	ArgsUsage: "<index>",
+	ArgsUsage: " ",
 	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  "pubkey",
-			Usage: "a master public key",
-			Value: "",
-		},
 		seedFlag,
-		formatFlag,
-		cli.BoolFlag{
-			Name:  "testnet",
-			Usage: "print testnet addrs",
-		},
+		networkFlag,
+		testnetFlag,
 	},
 	Action: func(c *cli.Context) error {
-		format := c.String("format")
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
 
-		if len(c.Args()) != 1 {
-			return fmt.Errorf("must pass in the derivation index")
+		sf, err := hdwrap.ReadSeedFile(c.String("seed"), seedPassphrase)
+		if err != nil {
+			return err
 		}
+		defer sf.Seed.Zero()
 
-		i, err := strconv.Atoi(c.Args().First())
+		fp, err := sf.Seed.Fingerprint()
 		if err != nil {
 			return err
 		}
 
-		testnet := c.Bool("testnet")
-		var k hdwrap.Key
-		if pubkey := c.String("pubkey"); pubkey != "" {
-			k, err = makeKeyFromPubKey(format, pubkey, testnet)
-			if err != nil {
-				return err
-			}
-		} else {
-			k, err = makeKeyFromSeed(format, c.String("seed"), testnet)
+		fmt.Printf("Scheme:      %s\n", sf.Scheme)
+		switch sf.Scheme {
+		case hdwrap.SchemeBIP39:
+			fmt.Printf("Language:    %s\n", sf.Language)
+		case hdwrap.SchemeElectrum:
+			t, err := hdwrap.ElectrumSeedTypeOf(sf.Phrase)
 			if err != nil {
 				return err
 			}
+			fmt.Printf("Wallet type: %s\n", t)
 		}
-
-		childpriv, err := k.GetChildPubKey(i)
+		if !sf.Created.IsZero() {
+			fmt.Printf("Created:     %s\n", sf.Created.Format("2006-01-02"))
+		}
+		if sf.Label != "" {
+			fmt.Printf("Label:       %s\n", sf.Label)
+		}
+		fmt.Printf("Fingerprint: %x\n", fp)
+		fmt.Println()
+
+		fmt.Printf("Master public keys (%s):\n", net)
+		for _, t := range hdwrap.KeyTypes() {
+			k := hdwrap.EmptyKey(t)
+			if err := k.SetNetwork(net); err != nil {
+				fmt.Printf("%-6s (network not supported)\n", t)
+				continue
+			}
+			err := keyFromSeedFile(k, sf, false)
+			if err == hdwrap.ErrWrongBIP39Passphrase {
+				fmt.Printf("%-6s (BIP39 passphrase needed)\n", t)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			pub, err := k.GetMasterPub()
+			if _, ok := err.(hdwrap.ErrNoExtendedKeys); ok {
+				fmt.Printf("%-6s (no extended keys)\n", t)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%-6s %s\n", t, pub)
+		}
+		return nil
+	},
+}
+
+var deriveChildCmd = cli.Command{
+	Name:  "derive-child",
+	Usage: "derive child seeds and keys from a seed (BIP85)",
+	Description: `
+This command derives independent child secrets from the seed, following
+BIP85. The master seed backup is enough to recover any of them, while
+knowing a child does not reveal the master seed or the other children.
+
+The kind of secret is given as argument:
+
+  bip39: a BIP39 mnemonic of --words words in the given --language. With
+         --output, a seed file is written for it too.
+  xprv:  a master extended private key.
+  wif:   a private key in Wallet Import Format.
+  hex:   --bytes bytes (16 to 64) of hex-encoded entropy.
+
+Different --index values produce different children.
+`,
+	ArgsUsage: "<bip39|xprv|wif|hex>",
+	Flags: []cli.Flag{
+		seedFlag,
+		cli.UintFlag{
+			Name:  "index",
+			Usage: "child index",
+		},
+		cli.IntFlag{
+			Name:  "words",
+			Usage: "number of words of BIP39 mnemonics",
+			Value: 24,
+		},
+		languageFlag,
+		cli.IntFlag{
+			Name:  "bytes",
+			Usage: "number of bytes of hex entropy",
+			Value: 64,
+		},
+		networkFlag,
+		testnetFlag,
+		cli.IntFlag{
+			Name:  "words-per-line",
+			Usage: "number of words to print per line",
+			Value: 6,
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "name of the seed file for child BIP39 mnemonics",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "replace any existing seed files",
+		},
+		passphraseFlag,
+		labelFlag,
+		encryptFlag,
+		kdfFlag,
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return fmt.Errorf("the kind of child (bip39, xprv, wif or hex) must be given")
+		}
+		app := c.Args().First()
+		if c.IsSet("output") && app != "bip39" {
+			return fmt.Errorf("--output can only be used with bip39 children")
+		}
+
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
+
+		seed, err := hdwrap.NewSeedFromFile(c.String("seed"), seedPassphrase)
+		if err != nil {
+			return err
+		}
+		defer seed.Zero()
+
+		index := uint32(c.Uint("index"))
+		switch app {
+		case "bip39":
+			lang := hdwrap.English
+			if c.IsSet("language") {
+				lang, err = hdwrap.ParseLanguage(c.String("language"))
+				if err != nil {
+					return err
+				}
+			}
+			entropy, err := seed.BIP85BIP39(lang, c.Int("words"), index)
+			if err != nil {
+				return err
+			}
+			sf, err := hdwrap.NewBIP39SeedFile(entropy, lang, c.String("passphrase"))
+			if err != nil {
+				return err
+			}
+			words, err := sf.Mnemonic()
+			if err != nil {
+				return err
+			}
+			wordsPerLine := c.Int("words-per-line")
+			hdwrap.PrintWords(words, wordsPerLine)
+			if len(words)%wordsPerLine != 0 {
+				fmt.Println()
+			}
+
+			if output := c.String("output"); output != "" {
+				sf.Created = time.Now()
+				err = writeSeedFile(c, sf, output)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Child seed has been written to \"%s\"\n", output)
+			}
+		case "xprv":
+			xprv, err := seed.BIP85XPRV(index, net)
+			if err != nil {
+				return err
+			}
+			fmt.Println(xprv)
+		case "wif":
+			wif, err := seed.BIP85WIF(index, net)
+			if err != nil {
+				return err
+			}
+			fmt.Println(wif)
+		case "hex":
+			entropy, err := seed.BIP85Hex(c.Int("bytes"), index)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%x\n", entropy)
+		default:
+			return fmt.Errorf("unknown kind of child: %s. Use bip39, xprv, wif or hex", app)
+		}
+		return nil
+	},
+}
+
+var privKeyCmd = cli.Command{
+	Name:  "priv",
+	Usage: "tools for working with HD master/derived private keys",
+	Subcommands: []cli.Command{
+		getMasterPrivCmd,
+		getChildPrivKeyCmd,
+	},
+}
+
+var getMasterPrivCmd = cli.Command{
+	Name:  "getmasterpriv",
+	Usage: "create the a master private key from the given seed",
+	Description: `
+This command prints an HD wallet private key given a generation seed. The
+output is specific to the chosen cryptocurrency and may not be compatible
+among different ones.
+
+Given the same seed and output format, the result is always the same.
+`,
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		seedFlag,
+		formatFlag,
+		addrTypeFlag,
+		networkFlag,
+		testnetFlag,
+	},
+	Action: func(c *cli.Context) error {
+		format := c.String("format")
+
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
+
+		k, err := makeKeyFromSeed(format, c.String("seed"), net)
+		if err != nil {
+			return err
+		}
+		if err := setAddressType(c, k); err != nil {
+			return err
+		}
+
+		masterpriv, err := k.GetMasterPriv()
+		if err != nil {
+			return err
+		}
+		fmt.Print(masterpriv)
+		return nil
+	},
+}
+
+var getChildPrivKeyCmd = cli.Command{
+	Name:  "child",
+	Usage: "derive a child private key",
+	Description: `
+This command derives a child private key from the given seed and format
+and prints it out. This can be imported into the different
+cryptocurrency wallets. See the README for more information.
+
+The key is derived as the non-hardened child <index> of the master key, or
+following the full derivation path given with --path (i.e. m/44'/0'/0'/0/5).
+Hardened path elements can be marked with ' or h.
+
+Alternatively, the --account, --change and --index options derive the key
+at the BIP44 path m/44'/coin'/account'/change/index, where coin is the
+SLIP-44 coin type for the chosen format.
+
+Cosmos keys are printed hex-encoded ("keys import-hex") or, with --armor,
+ASCII-armored and encrypted with a prompted passphrase ("keys import").
+`,
+	ArgsUsage: "<index>",
+	Flags: []cli.Flag{
+		seedFlag,
+		formatFlag,
+		addrTypeFlag,
+		pathFlag,
+		accountFlag,
+		changeFlag,
+		indexFlag,
+		networkFlag,
+		testnetFlag,
+		cli.BoolFlag{
+			Name:  "armor",
+			Usage: "for cosmos: print the key armored and encrypted with a passphrase",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		format := c.String("format")
+
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
+
+		k, err := makeKeyFromSeed(format, c.String("seed"), net)
+		if err != nil {
+			return err
+		}
+		if err := setAddressType(c, k); err != nil {
+			return err
+		}
+
+		p, err := childPath(c, k, false)
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("armor") {
+			ck, ok := k.(*hdwrap.CosmosKey)
+			if !ok {
+				return fmt.Errorf("--armor is only supported by cosmos keys")
+			}
+			pass, err := newPassphrase("armor")
+			if err != nil {
+				return err
+			}
+			armored, err := ck.ArmorChildPrivKey(p, string(pass))
+			if err != nil {
+				return err
+			}
+			fmt.Println(armored)
+			return nil
+		}
+
+		childpriv, err := k.GetChildPrivKey(p)
 		if err != nil {
 			return err
 		}
@@ -416,40 +1093,675 @@ the resulting address is always the same.
 	},
 }
 
-func makeKeyFromPubKey(format, pubkey string, testnet bool) (hdwrap.Key, error) {
+var pubKeyCmd = cli.Command{
+	Name:  "pub",
+	Usage: "tools for working with HD public keys and addresses",
+	Subcommands: []cli.Command{
+		getMasterPubCmd,
+		getChildPubKeyCmd,
+	},
+}
+
+var getMasterPubCmd = cli.Command{
+	Name:  "getmasterpub",
+	Usage: "obtain a master public key from the given seed",
+	Description: `
+This command prints an HD wallet public key given the key generation seed. The
+output is specific to the chosen cryptocurrency and may not be compatible
+among different ones.
+
+Given the same seed and output format, the result is always the same.
+`,
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		seedFlag,
+		formatFlag,
+		addrTypeFlag,
+		networkFlag,
+		testnetFlag,
+	},
+	Action: func(c *cli.Context) error {
+		format := c.String("format")
+
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
+
+		k, err := makeKeyFromSeed(format, c.String("seed"), net)
+		if err != nil {
+			return err
+		}
+		if err := setAddressType(c, k); err != nil {
+			return err
+		}
+
+		masterpub, err := k.GetMasterPub()
+		if err != nil {
+			return err
+		}
+		fmt.Print(masterpub)
+		return nil
+	},
+}
+
+var getChildPubKeyCmd = cli.Command{
+	Name:  "child",
+	Usage: "derive a child public key",
+	Description: `
+This command derives a child public key and formats it as a payment address.
+
+The command can take a -seed (default) or a -pubkey argument. When providing a
+master public key, it should be formatted for the desired cryptocurrency.
+Otherwise, the generated address will not work.
+
+Given the same seed or public key, the same derivation index and format,
+the resulting address is always the same.
+
+Instead of an index, a full derivation path can be given with --path
+(i.e. m/44'/0'/0'/0/5). Hardened path elements cannot be derived from
+a master public key.
+
+The --account, --change and --index options derive the address at the BIP44
+path m/44'/coin'/account'/change/index. When using --pubkey, this should be
+an account public key (see "account xpub") and only --change and --index
+are applied.
+`,
+	ArgsUsage: "<index>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "pubkey",
+			Usage: "a master or account public key",
+			Value: "",
+		},
+		seedFlag,
+		formatFlag,
+		addrTypeFlag,
+		hrpFlag,
+		pathFlag,
+		accountFlag,
+		changeFlag,
+		indexFlag,
+		networkFlag,
+		testnetFlag,
+	},
+	Action: func(c *cli.Context) error {
+		format := c.String("format")
+
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
+
+		pubkey := c.String("pubkey")
+		var k hdwrap.Key
+		if pubkey != "" {
+			k, err = makeKeyFromPubKey(format, pubkey, net)
+			if err != nil {
+				return err
+			}
+		} else {
+			k, err = makeKeyFromSeed(format, c.String("seed"), net)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := setAddressType(c, k); err != nil {
+			return err
+		}
+		if err := setHRP(c, k); err != nil {
+			return err
+		}
+
+		p, err := childPath(c, k, pubkey != "")
+		if err != nil {
+			return err
+		}
+
+		childpriv, err := k.GetChildPubKey(p)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(childpriv)
+		return nil
+	},
+}
+
+var accountCmd = cli.Command{
+	Name:  "account",
+	Usage: "tools for working with BIP44 accounts",
+	Subcommands: []cli.Command{
+		getAccountPubCmd,
+	},
+}
+
+var getAccountPubCmd = cli.Command{
+	Name:  "xpub",
+	Usage: "obtain the extended public key for an account",
+	Description: `
+This command prints the extended public key for the BIP44 account given with
+--account, which corresponds to the hardened path m/44'/coin'/account'. It can
+be handed to watch-only wallets, which can derive the account addresses but
+none of the private keys or the keys of other accounts.
+
+Addresses can be obtained from it with "pub child --pubkey <xpub> --change
+<change> --index <index>".
+`,
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		seedFlag,
+		formatFlag,
+		addrTypeFlag,
+		accountFlag,
+		networkFlag,
+		testnetFlag,
+	},
+	Action: func(c *cli.Context) error {
+		format := c.String("format")
+
+		account, err := bip44Index(c, "account")
+		if err != nil {
+			return err
+		}
+
+		net, err := network(c)
+		if err != nil {
+			return err
+		}
+
+		k, err := makeKeyFromSeed(format, c.String("seed"), net)
+		if err != nil {
+			return err
+		}
+		if err := setAddressType(c, k); err != nil {
+			return err
+		}
+
+		accountpub, err := k.GetAccountPub(account)
+		if err != nil {
+			return err
+		}
+		fmt.Println(accountpub)
+		return nil
+	},
+}
+
+var lndCmd = cli.Command{
+	Name:  "lnd",
+	Usage: "tools for working with lnd (Lightning Network) keys",
+	Subcommands: []cli.Command{
+		lndNodeIDCmd,
+		lndKeyCmd,
+	},
+}
+
+var lndFamilyFlag = cli.StringFlag{
+	Name:  "family",
+	Usage: "lnd key family: multisig, revocation-base, htlc-base, payment-base, delay-base, revocation-root, node, static-backup, tower-session, tower-id or a number",
+	Value: "node",
+}
+
+var lndNodeIDCmd = cli.Command{
+	Name:  "nodeid",
+	Usage: "print the identity public key of the lnd node",
+	Description: `
+This command prints the identity public key (node ID) of the lnd node using
+the seed, which is the key at m/1017'/coin'/6'/0/0. The seed file is usually
+imported from an aezeed mnemonic.
+`,
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		seedFlag,
+		networkFlag,
+		testnetFlag,
+	},
+	Action: func(c *cli.Context) error {
+		k, err := makeLndKey(c)
+		if err != nil {
+			return err
+		}
+		priv, err := k.GetLndPrivKey(hdwrap.LndFamilyNodeKey, 0)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%x\n", priv.PubKey().SerializeCompressed())
+		return nil
+	},
+}
+
+var lndKeyCmd = cli.Command{
+	Name:  "key",
+	Usage: "derive an lnd key",
+	Description: `
+This command derives the key of the given lnd key --family and --index, at
+m/1017'/coin'/family'/0/index, where coin is 0 for mainnet and 1 for test
+networks. It prints the path and the public key and, with --priv, the private
+key (hex-encoded).
+`,
+	ArgsUsage: " ",
+	Flags: []cli.Flag{
+		seedFlag,
+		lndFamilyFlag,
+		cli.UintFlag{
+			Name:  "index",
+			Usage: "key index",
+		},
+		cli.BoolFlag{
+			Name:  "priv",
+			Usage: "print the private key too",
+		},
+		networkFlag,
+		testnetFlag,
+	},
+	Action: func(c *cli.Context) error {
+		family, err := hdwrap.ParseLndKeyFamily(c.String("family"))
+		if err != nil {
+			return err
+		}
+		index, err := bip44Index(c, "index")
+		if err != nil {
+			return err
+		}
+		k, err := makeLndKey(c)
+		if err != nil {
+			return err
+		}
+
+		p := k.LndKeyPath(family, index)
+		priv, err := k.GetLndPrivKey(family, index)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Path:        %s\n", p)
+		fmt.Printf("Public key:  %x\n", priv.PubKey().SerializeCompressed())
+		if c.Bool("priv") {
+			fmt.Printf("Private key: %x\n", priv.Serialize())
+		}
+		return nil
+	},
+}
+
+var passwordCmd = cli.Command{
+	Name:  "password",
+	Usage: "derive a reproducible password from the seed (BIP85)",
+	Description: `
+This command derives a password for the given site label from the seed, using
+the BIP85 PWD BASE64 or PWD BASE85 applications. The same seed, site, --length
+and --counter always produce the same password, so it can be recovered from
+the seed backup. Increase --counter to change the password of a site.
+
+The BIP85 index of the password is the first 31 bits of the SHA256 hash of
+"<site>/<counter>". A BIP85 --index can be given instead of the site label,
+to obtain the passwords produced by other BIP85 implementations.
+`,
+	ArgsUsage: "<site>",
+	Flags: []cli.Flag{
+		seedFlag,
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "password format: base64 (20-86 characters) or base85 (10-80 characters)",
+			Value: "base64",
+		},
+		cli.IntFlag{
+			Name:  "length",
+			Usage: "password length",
+			Value: 21,
+		},
+		cli.UintFlag{
+			Name:  "counter",
+			Usage: "password counter for the site",
+		},
+		cli.UintFlag{
+			Name:  "index",
+			Usage: "BIP85 index, instead of a site label",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		f, err := hdwrap.ParsePasswordFormat(c.String("format"))
+		if err != nil {
+			return err
+		}
+
+		var index uint32
+		switch {
+		case c.IsSet("index") && (c.NArg() > 0 || c.IsSet("counter")):
+			return fmt.Errorf("--index cannot be used along with a site label or --counter")
+		case c.IsSet("index"):
+			index = uint32(c.Uint("index"))
+		case c.NArg() == 1:
+			index = hdwrap.SitePasswordIndex(c.Args().First(), uint32(c.Uint("counter")))
+		default:
+			return fmt.Errorf("a site label or an --index must be given")
+		}
+
+		seed, err := hdwrap.NewSeedFromFile(c.String("seed"), seedPassphrase)
+		if err != nil {
+			return err
+		}
+		defer seed.Zero()
+
+		pwd, err := seed.BIP85Password(f, c.Int("length"), index)
+		if err != nil {
+			return err
+		}
+		fmt.Println(pwd)
+		return nil
+	},
+}
+
+// childPath returns the derivation path given with --path, the BIP44 path
+// given with --account, --change and --index or, otherwise, a
+// single-element path with the index passed as argument. When deriving from
+// an account public key, the BIP44 path is relative to it (change/index).
+func childPath(c *cli.Context, k hdwrap.Key, fromPubKey bool) (hdwrap.Path, error) {
+	bip44 := c.IsSet("account") || c.IsSet("change") || c.IsSet("index")
+	path := c.String("path")
+
+	switch {
+	case path != "" && bip44:
+		return nil, fmt.Errorf("cannot pass both --path and BIP44 options")
+	case (path != "" || bip44) && len(c.Args()) != 0:
+		return nil, fmt.Errorf("cannot pass both a derivation index and a path")
+	case path != "":
+		return hdwrap.ParsePath(path)
+	case bip44:
+		account, err := bip44Index(c, "account")
+		if err != nil {
+			return nil, err
+		}
+		change, err := bip44Index(c, "change")
+		if err != nil {
+			return nil, err
+		}
+		index, err := bip44Index(c, "index")
+		if err != nil {
+			return nil, err
+		}
+		if fromPubKey {
+			if c.IsSet("account") {
+				return nil, fmt.Errorf("--account cannot be used with --pubkey")
+			}
+			return hdwrap.Path{change, index}, nil
+		}
+		if bk, ok := k.(*hdwrap.BtcKey); ok {
+			if err := bk.CheckAccount(account); err != nil {
+				return nil, err
+			}
+		}
+		return k.AddressPath(account, change, index), nil
+	}
+
+	if len(c.Args()) != 1 {
+		return nil, fmt.Errorf("must pass in the derivation index or --path")
+	}
+
+	p, err := hdwrap.ParsePath(c.Args().First())
+	if err != nil {
+		return nil, err
+	}
+	if len(p) != 1 {
+		return nil, fmt.Errorf("the derivation index must be a single number")
+	}
+	return p, nil
+}
+
+// bip44Index returns the value of the given BIP44 path option, making sure
+// it is not in the hardened range.
+func bip44Index(c *cli.Context, name string) (uint32, error) {
+	v := c.Uint(name)
+	if v >= uint(hdwrap.HardenedKeyStart) {
+		return 0, fmt.Errorf("--%s is out of range", name)
+	}
+	return uint32(v), nil
+}
+
+// setAddressType applies the --addrtype option to the given key.
+func setAddressType(c *cli.Context, k hdwrap.Key) error {
+	addrtype := c.String("addrtype")
+	if addrtype == "" {
+		return nil
+	}
+
+	t, err := hdwrap.ParseAddressType(addrtype)
+	if err != nil {
+		return err
+	}
+
+	at, ok := k.(hdwrap.AddressTyper)
+	if !ok {
+		return fmt.Errorf("%s keys do not support address types", k.Type())
+	}
+	return at.SetAddressType(t)
+}
+
+// setHRP applies the --hrp option to cosmos keys.
+func setHRP(c *cli.Context, k hdwrap.Key) error {
+	ck, ok := k.(*hdwrap.CosmosKey)
+	if !ok {
+		if c.IsSet("hrp") {
+			return fmt.Errorf("--hrp is only supported by cosmos keys")
+		}
+		return nil
+	}
+	return ck.SetHRP(c.String("hrp"))
+}
+
+// network returns the network selected with --network, or with the
+// deprecated --testnet flag.
+func network(c *cli.Context) (hdwrap.Network, error) {
+	if c.Bool("testnet") {
+		if c.IsSet("network") {
+			return 0, fmt.Errorf("cannot pass both --testnet and --network")
+		}
+		return hdwrap.Testnet3, nil
+	}
+	return hdwrap.ParseNetwork(c.String("network"))
+}
+
+// makeKeyFromPubKey imports an extended public key. The network is
+// switched to the one the key belongs to, if it differs.
+func makeKeyFromPubKey(format, pubkey string, net hdwrap.Network) (hdwrap.Key, error) {
 	k := hdwrap.EmptyKeyStr(format)
-	err := k.FromString(pubkey, false)
+	err := k.SetNetwork(net)
+	if err != nil {
+		return nil, err
+	}
+	err = k.FromString(pubkey, false)
 	if err != nil {
 		return nil, err
 	}
-	k.SetTestNet(testnet)
 	return k, nil
 }
 
-func makeKeyFromPrivKey(format, pkeyfile string, testnet bool) (hdwrap.Key, error) {
+func makeKeyFromPrivKey(format, pkeyfile string, net hdwrap.Network) (hdwrap.Key, error) {
 	keyBytes, err := ioutil.ReadFile(pkeyfile)
 	if err != nil {
 		return nil, err
 	}
 	k := hdwrap.EmptyKeyStr(format)
+	err = k.SetNetwork(net)
+	if err != nil {
+		return nil, err
+	}
 	err = k.FromString(string(keyBytes), true)
 	if err != nil {
 		return nil, err
 	}
-	k.SetTestNet(testnet)
 	return k, nil
 }
 
-func makeKeyFromSeed(format, seedfile string, testnet bool) (hdwrap.Key, error) {
-	seed, err := hdwrap.NewSeedFromFile(seedfile)
+// makeKeyFromSeed creates a master key from the seed file. The network
+// is set before, so that the master key is created for it. Bitcoin keys
+// from Electrum seeds follow the Electrum wallet derivation paths.
+func makeKeyFromSeed(format, seedfile string, net hdwrap.Network) (hdwrap.Key, error) {
+	sf, err := hdwrap.ReadSeedFile(seedfile, seedPassphrase)
 	if err != nil {
 		return nil, err
 	}
 	k := hdwrap.EmptyKeyStr(format)
-	err = k.FromSeed(seed)
+	err = k.SetNetwork(net)
 	if err != nil {
 		return nil, err
 	}
-	k.SetTestNet(testnet)
+	err = keyFromSeedFile(k, sf, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if bk, ok := k.(*hdwrap.BtcKey); ok && sf.Scheme == hdwrap.SchemeElectrum {
+		t, err := hdwrap.ElectrumSeedTypeOf(sf.Phrase)
+		if err != nil {
+			return nil, err
+		}
+		bk.SetElectrumSeedType(t)
+	}
 	return k, nil
 }
+
+// keyFromSeedFile initializes the key with the seed of the seed file.
+// Cardano keys from BIP39 seed files are derived from the mnemonic entropy
+// instead (Icarus), which needs the BIP39 passphrase. It is prompted for,
+// if prompt is set, when the seed was derived with one.
+func keyFromSeedFile(k hdwrap.Key, sf *hdwrap.SeedFile, prompt bool) error {
+	ak, ok := k.(*hdwrap.AdaKey)
+	if !ok || sf.Scheme != hdwrap.SchemeBIP39 {
+		return k.FromSeed(sf.Seed)
+	}
+	err := ak.FromBIP39(sf, "")
+	if err != hdwrap.ErrWrongBIP39Passphrase || !prompt {
+		return err
+	}
+	pass, err := readPassphrase("BIP39 passphrase: ")
+	if err != nil {
+		return err
+	}
+	return ak.FromBIP39(sf, string(pass))
+}
+
+// makeLndKey creates the Bitcoin master key from which lnd keys are
+// derived, for the --seed file and --network.
+func makeLndKey(c *cli.Context) (*hdwrap.BtcKey, error) {
+	net, err := network(c)
+	if err != nil {
+		return nil, err
+	}
+	k, err := makeKeyFromSeed("btc", c.String("seed"), net)
+	if err != nil {
+		return nil, err
+	}
+	return k.(*hdwrap.BtcKey), nil
+}
+
+// readRolls reads dice rolls or coin flips from the standard input until
+// an empty line or EOF, guiding the user when it is a terminal. It fails
+// when the results do not provide the given bits of entropy.
+func readRolls(die hdwrap.Die, bits int) ([]int, error) {
+	interactive := terminal.IsTerminal(int(os.Stdin.Fd()))
+	if interactive {
+		fmt.Fprintf(os.Stderr, "Enter at least %d %s results. Finish with an empty line.\n", die.RollsNeeded(bits), die)
+	}
+
+	var rolls []int
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			if interactive {
+				break
+			}
+			continue
+		}
+		r, err := die.ParseRolls(line)
+		if err != nil {
+			if !interactive {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "%s. Line ignored.\n", err)
+			continue
+		}
+		rolls = append(rolls, r...)
+		if interactive {
+			fmt.Fprintf(os.Stderr, "%d results (%.1f of %d bits)\n", len(rolls), die.EntropyBits(len(rolls)), bits)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := die.CheckEntropy(rolls, bits); err != nil {
+		return nil, err
+	}
+	if err := die.CheckBias(rolls); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %s. Check your dice (or coins) and your input.\n", err)
+	}
+	return rolls, nil
+}
+
+// writeSeedFile writes a seed file with the --label and --created
+// metadata, encrypting it with a prompted passphrase when --encrypt is set.
+func writeSeedFile(c *cli.Context, sf *hdwrap.SeedFile, output string) error {
+	sf.Label = c.String("label")
+	if c.IsSet("created") {
+		created, err := time.Parse("2006-01-02", c.String("created"))
+		if err != nil {
+			return fmt.Errorf("bad creation date: %s", err)
+		}
+		sf.Created = created
+	}
+
+	if !c.Bool("encrypt") {
+		return sf.WriteToFile(output, c.Bool("overwrite"))
+	}
+
+	kdf, err := hdwrap.ParseKDF(c.String("kdf"))
+	if err != nil {
+		return err
+	}
+	pass, err := newPassphrase("seed file")
+	if err != nil {
+		return err
+	}
+	return sf.WriteEncryptedToFile(output, pass, kdf, c.Bool("overwrite"))
+}
+
+// seedPassphrase prompts for the passphrase of encrypted seed files.
+func seedPassphrase() ([]byte, error) {
+	return readPassphrase("Seed file passphrase: ")
+}
+
+// newPassphrase prompts twice for a new passphrase for the given purpose
+// (i.e. "seed file").
+func newPassphrase(what string) ([]byte, error) {
+	pass, err := readPassphrase(fmt.Sprintf("New %s passphrase: ", what))
+	if err != nil {
+		return nil, err
+	}
+	confirm, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pass, confirm) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+	return pass, nil
+}
+
+// readPassphrase reads a passphrase from the terminal without echoing
+// it. The terminal is used even when the standard input is redirected.
+func readPassphrase(prompt string) ([]byte, error) {
+	in, out := os.Stdin, os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		defer tty.Close()
+		in, out = tty, tty
+	}
+
+	fmt.Fprint(out, prompt)
+	pass, err := terminal.ReadPassword(int(in.Fd()))
+	fmt.Fprintln(out)
+	if err != nil {
+		return nil, fmt.Errorf("error reading passphrase: %s", err)
+	}
+	return pass, nil
+}